@@ -0,0 +1,44 @@
+/*
+Copyright © 2025 Dyam Marcano dyam.marcano@gmail.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/inovacc/goinstall/internal/installer"
+	"github.com/spf13/cobra"
+)
+
+// adoptCmd represents the adopt command
+var adoptCmd = &cobra.Command{
+	Use:   "adopt",
+	Short: "Track the Go binaries already installed in GOBIN",
+	Long: `Scan GOBIN for Go binaries goinstall does not track yet and record them
+from the build information embedded in each binary: the package and module
+version it was built from, the Go version and its module dependencies.
+
+Adopted modules show up in report and can be updated with --update. Binaries
+built from a local checkout carry no version and are skipped, as are binaries
+renamed after install.`,
+	Args: cobra.NoArgs,
+	RunE: installer.Adopt,
+}
+
+func init() {
+	rootCmd.AddCommand(adoptCmd)
+
+	adoptCmd.Flags().Bool("dry-run", false, "Show what would be adopted without recording it")
+	adoptCmd.Flags().Bool("offline", false, "Do not look up the published versions")
+}
@@ -0,0 +1,51 @@
+/*
+Copyright © 2025 Dyam Marcano dyam.marcano@gmail.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/inovacc/goinstall/internal/audit"
+	"github.com/inovacc/goinstall/internal/printer"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// auditCmd represents the audit command
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Check installed modules against an OSV vulnerability database",
+	Long: `Check every installed module and the recorded versions of its dependencies
+against a local OSV vulnerability database: a directory of OSV JSON files or
+a zip archive of them, such as https://osv-vulnerabilities.storage.googleapis.com/Go/all.zip.
+
+Each finding shows the fixed version and whether updating the tool with
+--update would fix it. Without --offline the go.mod of the update is fetched
+from the module proxy to tell; the database itself is never fetched.
+
+The command exits with an error when vulnerabilities are found.`,
+	Args: cobra.NoArgs,
+	RunE: audit.Audit,
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+
+	auditCmd.Flags().StringP("database", "d", "", "OSV database directory or zip file")
+	auditCmd.Flags().Bool("offline", false, "Do not contact the module proxy")
+	auditCmd.Flags().StringP("output", "o", printer.FormatTable, "Output format: table, json or yaml")
+
+	cobra.CheckErr(viper.BindPFlag("audit.database", auditCmd.Flags().Lookup("database")))
+}
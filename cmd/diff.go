@@ -0,0 +1,46 @@
+/*
+Copyright © 2025 Dyam Marcano dyam.marcano@gmail.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/inovacc/goinstall/internal/inventory"
+	"github.com/inovacc/goinstall/internal/printer"
+	"github.com/spf13/cobra"
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff <inventory> [inventory]",
+	Short: "Compare two inventories, or an inventory with the local modules",
+	Long: `Compare two inventories written by goinstall export, or one inventory
+(left) with the modules installed locally (right).
+
+Tools installed on one side only, version, build tag and alias differences,
+and dependency versions that drifted between the same tool on both sides are
+listed:
+
+  goinstall export > mine.json
+  goinstall diff theirs.json mine.json`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: inventory.Diff,
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringP("output", "o", printer.FormatTable, "Output format: table, json or yaml")
+}
@@ -0,0 +1,37 @@
+/*
+Copyright © 2025 Dyam Marcano dyam.marcano@gmail.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/inovacc/goinstall/internal/installer"
+	"github.com/spf13/cobra"
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export [file]",
+	Short: "Export every installed module to a JSON inventory",
+	Long: `Write every installed module, with its version, dependencies and build
+settings, to one JSON document. The inventory goes to stdout unless a file
+is given, and can be installed on another machine with goinstall import.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: installer.Export,
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+}
@@ -0,0 +1,47 @@
+/*
+Copyright © 2025 Dyam Marcano dyam.marcano@gmail.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/inovacc/goinstall/internal/graph"
+	"github.com/spf13/cobra"
+)
+
+// graphCmd represents the graph command
+var graphCmd = &cobra.Command{
+	Use:   "graph [module]",
+	Short: "Render the dependency graph of installed modules",
+	Long: `Render the dependency graph recorded for a module, or for every installed
+module combined, as Graphviz DOT, Mermaid or JSON adjacency.
+
+Use --depth to stop a number of requirements away from the tools and
+--prefix to keep only the modules with a path prefix and the paths that
+lead to them. For example:
+
+  goinstall graph mvdan.cc/gofumpt | dot -Tsvg > gofumpt.svg
+  goinstall graph --format mermaid --prefix golang.org/x/`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: graph.Graph,
+}
+
+func init() {
+	rootCmd.AddCommand(graphCmd)
+
+	graphCmd.Flags().StringP("format", "f", graph.FormatDOT, "Output format: dot, mermaid or json")
+	graphCmd.Flags().IntP("depth", "d", 0, "Maximum depth from the tools, 0 for no limit")
+	graphCmd.Flags().StringSliceP("prefix", "p", nil, "Keep modules whose path starts with prefix and the paths to them")
+}
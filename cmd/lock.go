@@ -0,0 +1,43 @@
+/*
+Copyright © 2025 Dyam Marcano dyam.marcano@gmail.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/inovacc/goinstall/internal/installer"
+	"github.com/spf13/cobra"
+)
+
+// lockCmd represents the lock command
+var lockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Pin the manifest tools to exact versions in goinstall.lock",
+	Long: `Resolve every tool of the manifest to an exact version and write it to
+goinstall.lock next to the manifest, with the h1: hash of its module and the
+versions of its dependencies. Installed tools that meet the manifest are
+locked at their installed version.
+
+Commit the lockfile and run goinstall sync --frozen to install the same
+versions everywhere.`,
+	Args: cobra.NoArgs,
+	RunE: installer.Lock,
+}
+
+func init() {
+	rootCmd.AddCommand(lockCmd)
+
+	lockCmd.Flags().StringP("file", "f", "", "Manifest file (default is ./goinstall.yaml or ./goinstall.toml)")
+}
@@ -17,28 +17,40 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
 	"github.com/inovacc/goinstall/internal/monitor"
-
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"time"
 )
 
 // monitorCmd represents the monitor command
 var monitorCmd = &cobra.Command{
 	Use:   "monitor",
-	Short: "A brief description of your command",
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
+	Short: "Watch tracked modules for new upstream versions",
+	Long: `Watch tracked modules for new upstream versions.
+
+The monitor polls the upstream versions of every module tracked by
+goinstall on the configured interval, records each newly seen version in
+the database and logs it. It runs until interrupted with SIGINT or SIGTERM.
+
+What happens with a new version is decided by the module policy, set in the
+config file under monitor.policies (default monitor.policy):
+
+  notify  only log and record the new version
+  patch   install new patch releases of the installed minor version
+  minor   install new minor and patch releases of the installed major version
 
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
+A new major version is never installed automatically.`,
 	RunE: monitor.Monitor,
 }
 
 func init() {
-	monitorCmd.SetOut(new(bytes.Buffer))
-	monitorCmd.SetErr(new(bytes.Buffer))
-
 	rootCmd.AddCommand(monitorCmd)
+
+	monitorCmd.Flags().Duration("interval", time.Hour, "Time between version checks")
+	monitorCmd.Flags().Bool("once", false, "Check once and exit")
+	monitorCmd.Flags().String("policy", string(monitor.PolicyNotify), "Policy for modules without their own: notify, patch or minor")
+
+	cobra.CheckErr(viper.BindPFlag("monitor.interval", monitorCmd.Flags().Lookup("interval")))
+	cobra.CheckErr(viper.BindPFlag("monitor.policy", monitorCmd.Flags().Lookup("policy")))
 }
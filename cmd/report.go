@@ -17,36 +17,26 @@ limitations under the License.
 package cmd
 
 import (
-	"fmt"
-
+	"github.com/inovacc/goinstall/internal/printer"
+	"github.com/inovacc/goinstall/internal/report"
 	"github.com/spf13/cobra"
 )
 
 // reportCmd represents the report command
 var reportCmd = &cobra.Command{
-	Use:   "report",
-	Short: "A brief description of your command",
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
-
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("report called")
-	},
+	Use:   "report [module]",
+	Short: "Show the modules tracked by goinstall",
+	Long: `Show the modules tracked by goinstall.
+
+Without arguments every installed module is listed together with its
+install time and the latest version known upstream. With a module argument
+the full record is shown, including known versions and dependencies.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: report.Report,
 }
 
 func init() {
 	rootCmd.AddCommand(reportCmd)
 
-	// Here you will define your flags and configuration settings.
-
-	// Cobra supports Persistent Flags which will work for this command
-	// and all subcommands, e.g.:
-	// reportCmd.PersistentFlags().String("foo", "", "A help for foo")
-
-	// Cobra supports local flags which will only run when this command
-	// is called directly, e.g.:
-	// reportCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	reportCmd.Flags().StringP("output", "o", printer.FormatTable, "Output format: table, json or yaml")
 }
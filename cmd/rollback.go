@@ -0,0 +1,45 @@
+/*
+Copyright © 2025 Dyam Marcano dyam.marcano@gmail.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/inovacc/goinstall/internal/installer"
+	"github.com/spf13/cobra"
+)
+
+// rollbackCmd represents the rollback command
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback <module>",
+	Short: "Switch a module back to a previously installed version",
+	Long: `Switch a module back to the version installed before the current one, or
+to the version given with --to.
+
+Every installed version is kept in the store next to the database and the
+GOBIN entry points at the active one, so the switch needs no network fetch or
+rebuild. Rolling back twice returns to the version rolled back from:
+
+  goinstall rollback mvdan.cc/gofumpt
+  goinstall rollback mvdan.cc/gofumpt --to v0.8.0`,
+	Args: cobra.ExactArgs(1),
+	RunE: installer.Rollback,
+}
+
+func init() {
+	rootCmd.AddCommand(rollbackCmd)
+
+	rollbackCmd.Flags().String("to", "", "Version to switch to instead of the previous one")
+}
@@ -17,15 +17,23 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/inovacc/goinstall/internal/installer"
+	"github.com/inovacc/goinstall/internal/shim"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"os"
+	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"runtime"
+	"syscall"
 )
 
+var cfgFile string
+
 var rootCmd = &cobra.Command{
 	Use:   "goinstall",
 	Short: "Install, update or remove Go modules with ease",
@@ -51,12 +59,35 @@ from your environment with a clean and idiomatic approach.`,
 }
 
 func Execute() {
-	cobra.CheckErr(rootCmd.Execute())
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Started through a shim, goinstall runs the tool it stands for.
+	if ok, err := shim.Run(ctx, os.Args); ok {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.ExitCode())
+		}
+		cobra.CheckErr(err)
+		return
+	}
+
+	cobra.CheckErr(rootCmd.ExecuteContext(ctx))
 }
 
 func init() {
+	cobra.OnInitialize(initConfig)
+
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
 
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "Config file (default is $XDG_CONFIG_HOME/goinstall/config.yaml)")
+
+	rootCmd.PersistentFlags().Int("dependency-jobs", 8, "Number of dependencies resolved at once")
+	cobra.CheckErr(viper.BindPFlag("dependencies.jobs", rootCmd.PersistentFlags().Lookup("dependency-jobs")))
+
+	rootCmd.PersistentFlags().Bool("allow-denied-licenses", false, "Install modules pulling in licenses of the license.deny setting")
+	cobra.CheckErr(viper.BindPFlag("license.allow", rootCmd.PersistentFlags().Lookup("allow-denied-licenses")))
+
 	rootCmd.Flags().BoolP("remove", "r", false, "Remove go install module")
 	rootCmd.Flags().BoolP("update", "u", false, "Update go install module")
 
@@ -66,6 +97,26 @@ func init() {
 	viper.Set("installPath", dbPath())
 }
 
+// initConfig reads the config file when one exists. Running without a config
+// file is fine, every setting has a default.
+func initConfig() {
+	if cfgFile != "" {
+		viper.SetConfigFile(cfgFile)
+	} else {
+		if dir, err := os.UserConfigDir(); err == nil {
+			viper.AddConfigPath(filepath.Join(dir, "goinstall"))
+		}
+		viper.SetConfigName("config")
+	}
+
+	if err := viper.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if !errors.As(err, &notFound) {
+			cobra.CheckErr(fmt.Errorf("failed to read config: %w", err))
+		}
+	}
+}
+
 func dbPath() string {
 	if custom := os.Getenv("GOINSTALL_DB_PATH"); custom != "" {
 		return custom
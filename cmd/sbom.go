@@ -0,0 +1,45 @@
+/*
+Copyright © 2025 Dyam Marcano dyam.marcano@gmail.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/inovacc/goinstall/internal/sbom"
+	"github.com/spf13/cobra"
+)
+
+// sbomCmd represents the sbom command
+var sbomCmd = &cobra.Command{
+	Use:   "sbom [module]",
+	Short: "Write a software bill of materials of the installed tools",
+	Long: `Write a software bill of materials of one installed tool, or of every
+installed tool, as a CycloneDX 1.5 or SPDX 2.3 JSON document.
+
+Components are the tools and the module versions they were built with,
+identified by their pkg:golang purl, with the requirements between them as
+dependency relationships. Tools whose binary was recorded carry its SHA-256:
+
+  goinstall sbom mvdan.cc/gofumpt > gofumpt.cdx.json
+  goinstall sbom --format spdx > tools.spdx.json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: sbom.Sbom,
+}
+
+func init() {
+	rootCmd.AddCommand(sbomCmd)
+
+	sbomCmd.Flags().StringP("format", "f", sbom.FormatCycloneDX, "Document format: cyclonedx or spdx")
+}
@@ -0,0 +1,57 @@
+/*
+Copyright © 2025 Dyam Marcano dyam.marcano@gmail.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/inovacc/goinstall/internal/installer"
+	"github.com/spf13/cobra"
+)
+
+// syncCmd represents the sync command
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Install the tools listed in a goinstall.yaml or goinstall.toml manifest",
+	Long: `Bring the installed modules in line with a manifest, by default the
+goinstall.yaml or goinstall.toml in the working directory:
+
+  tools:
+    - module: mvdan.cc/gofumpt
+      version: ^0.9
+    - module: golang.org/x/tools/cmd/stringer
+      tags: [netgo]
+      alias: stringer-x
+
+Missing tools are installed. Tools whose installed version does not meet the
+version query, or whose build tags or alias differ, are reinstalled. A tool
+without a version accepts any installed version. With --prune, installed
+modules the manifest does not list are removed.
+
+With --frozen the tools are installed at the exact versions of the
+goinstall.lock written by goinstall lock, and sync fails when the lock is
+stale or a module hash no longer matches upstream.`,
+	Args: cobra.NoArgs,
+	RunE: installer.Sync,
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+
+	syncCmd.Flags().StringP("file", "f", "", "Manifest file (default is ./goinstall.yaml or ./goinstall.toml)")
+	syncCmd.Flags().Bool("dry-run", false, "Print the plan without running it")
+	syncCmd.Flags().Bool("prune", false, "Remove installed modules not listed in the manifest")
+	syncCmd.Flags().Bool("frozen", false, "Install the versions of goinstall.lock and verify their hashes")
+}
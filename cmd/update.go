@@ -0,0 +1,45 @@
+/*
+Copyright © 2025 Dyam Marcano dyam.marcano@gmail.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/inovacc/goinstall/internal/installer"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// updateCmd represents the update command
+var updateCmd = &cobra.Command{
+	Use:   "update [module...]",
+	Short: "Upgrade tracked modules to their newest version",
+	Long: `Upgrade tracked modules to their newest version.
+
+Pass one or more modules, or --all to check every module tracked by
+goinstall. Checks run concurrently and a summary of upgraded, current and
+failed modules is printed at the end. The command exits non-zero when any
+upgrade failed.`,
+	RunE: installer.Update,
+}
+
+func init() {
+	rootCmd.AddCommand(updateCmd)
+
+	updateCmd.Flags().BoolP("all", "a", false, "Update every tracked module")
+	updateCmd.Flags().IntP("jobs", "j", 4, "Number of modules checked at once")
+
+	cobra.CheckErr(viper.BindPFlag("update.jobs", updateCmd.Flags().Lookup("jobs")))
+}
@@ -0,0 +1,50 @@
+/*
+Copyright © 2025 Dyam Marcano dyam.marcano@gmail.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/inovacc/goinstall/internal/installer"
+	"github.com/spf13/cobra"
+)
+
+// useCmd represents the use command
+var useCmd = &cobra.Command{
+	Use:   "use <module>@<version>",
+	Short: "Select the installed version of a module that runs",
+	Long: `Select which of the installed versions of a module runs. Install each
+version first; all of them are kept side by side in the store.
+
+Without flags the version becomes the global default. With --local it is
+pinned in the .goinstall-version file of the current directory instead, and
+the GOBIN entry of the module becomes a shim: a link to goinstall that runs
+the version pinned by the closest .goinstall-version file up from the working
+directory, or the global default:
+
+  goinstall google.golang.org/protobuf/cmd/protoc-gen-go@v1.34.2
+  goinstall google.golang.org/protobuf/cmd/protoc-gen-go@v1.36.6
+  goinstall use --local google.golang.org/protobuf/cmd/protoc-gen-go@v1.34.2
+  goinstall use --shim=false google.golang.org/protobuf/cmd/protoc-gen-go`,
+	Args: cobra.ExactArgs(1),
+	RunE: installer.Use,
+}
+
+func init() {
+	rootCmd.AddCommand(useCmd)
+
+	useCmd.Flags().Bool("local", false, "Pin the version in .goinstall-version of the current directory")
+	useCmd.Flags().Bool("shim", false, "Make the GOBIN entry a shim, or a plain link with --shim=false")
+}
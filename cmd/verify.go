@@ -0,0 +1,43 @@
+/*
+Copyright © 2025 Dyam Marcano dyam.marcano@gmail.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/inovacc/goinstall/internal/printer"
+	"github.com/inovacc/goinstall/internal/verify"
+	"github.com/spf13/cobra"
+)
+
+// verifyCmd represents the verify command
+var verifyCmd = &cobra.Command{
+	Use:   "verify [module...]",
+	Short: "Check installed binaries against the checksums recorded at install",
+	Long: `Re-hash the binary of every installed module, or of the given ones, and
+compare it with the SHA-256 recorded when it was installed.
+
+Each binary is reported as ok, modified (same module version, different
+content), replaced (another module, version or no Go binary at all) or
+missing. The command fails when any binary does not match. Modules installed
+before checksums were recorded are reported as unrecorded.`,
+	RunE: verify.Verify,
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+
+	verifyCmd.Flags().StringP("output", "o", printer.FormatTable, "Output format: table, json or yaml")
+}
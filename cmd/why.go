@@ -0,0 +1,44 @@
+/*
+Copyright © 2025 Dyam Marcano dyam.marcano@gmail.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/inovacc/goinstall/internal/graph"
+	"github.com/inovacc/goinstall/internal/printer"
+	"github.com/spf13/cobra"
+)
+
+// whyCmd represents the why command
+var whyCmd = &cobra.Command{
+	Use:     "why <module>",
+	Aliases: []string{"rdeps"},
+	Short:   "Show which installed modules depend on a module",
+	Long: `Show which installed modules depend on a module, the version each of
+them pulls in and the requirement path from the tool down to the module.
+
+Useful when a vulnerability or a broken release lands in a library:
+
+  goinstall why golang.org/x/net`,
+	Args: cobra.ExactArgs(1),
+	RunE: graph.Why,
+}
+
+func init() {
+	rootCmd.AddCommand(whyCmd)
+
+	whyCmd.Flags().StringP("output", "o", printer.FormatTable, "Output format: table, json or yaml")
+}
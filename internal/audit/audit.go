@@ -0,0 +1,210 @@
+package audit
+
+import (
+	"fmt"
+	"github.com/inovacc/goinstall/internal/database"
+	"github.com/inovacc/goinstall/internal/module"
+	"github.com/inovacc/goinstall/internal/printer"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/mod/semver"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	fixYes     = "yes"
+	fixNo      = "no"
+	fixUnknown = "unknown"
+)
+
+var afs afero.Fs
+
+// Finding is a vulnerability in a module recorded for an installed tool.
+type Finding struct {
+	Tool        string   `json:"tool"`
+	ToolVersion string   `json:"toolVersion"`
+	Module      string   `json:"module"`
+	Version     string   `json:"version"`
+	ID          string   `json:"id"`
+	Aliases     []string `json:"aliases,omitempty"`
+	Summary     string   `json:"summary"`
+	Fixed       string   `json:"fixed"`
+	// Update is the version goinstall --update would install, and
+	// UpdateFixes whether that version no longer contains the vulnerability.
+	Update      string `json:"update"`
+	UpdateFixes string `json:"updateFixes"`
+}
+
+// Audit checks every installed tool and its recorded dependencies against the
+// OSV database configured in audit.database. Findings make it fail, so it
+// can gate CI.
+func Audit(cmd *cobra.Command, args []string) error {
+	afs = afero.NewOsFs()
+
+	path := viper.GetString("audit.database")
+	if path == "" {
+		return fmt.Errorf("no vulnerability database given, use --database or set audit.database in the config file")
+	}
+
+	vulns, err := LoadDatabase(afs, path)
+	if err != nil {
+		return err
+	}
+	for _, err := range vulns.Skipped() {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %v\n", err)
+	}
+
+	db, err := database.NewDatabase(cmd.Context(), afs)
+	if err != nil {
+		return err
+	}
+	defer func(db *database.Database) {
+		cobra.CheckErr(db.Close())
+	}(db)
+
+	installed, err := module.LoadInstalled(db)
+	if err != nil {
+		return err
+	}
+
+	offline, _ := cmd.Flags().GetBool("offline")
+	format, _ := cmd.Flags().GetString("output")
+
+	var findings []Finding
+	for i := range installed {
+		m, err := loadTool(cmd, db, installed[i].Name, offline)
+		if err != nil {
+			return err
+		}
+		findings = append(findings, scan(cmd.ErrOrStderr(), vulns, m, offline)...)
+	}
+
+	if len(findings) == 0 && format == printer.FormatTable {
+		cmd.Printf("No known vulnerabilities in %d installed modules\n", len(installed))
+		return nil
+	}
+
+	err = printer.Print(cmd.OutOrStdout(), format, findings, func(tw *tabwriter.Writer) {
+		_, _ = fmt.Fprintln(tw, "TOOL\tMODULE\tVERSION\tID\tFIXED\tUPDATE\tUPDATE FIXES")
+		for _, f := range findings {
+			_, _ = fmt.Fprintf(tw, "%s@%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				f.Tool, f.ToolVersion, f.Module, f.Version, f.ID, orNone(f.Fixed), orNone(f.Update), f.UpdateFixes)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(findings) > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("found %d vulnerabilities in %d installed modules", len(findings), countTools(findings))
+	}
+	return nil
+}
+
+// loadTool returns the record of the tool called name with its graph. Online
+// it can also fetch the go.mod of the version an update would install.
+func loadTool(cmd *cobra.Command, db *database.Database, name string, offline bool) (*module.Module, error) {
+	if offline {
+		return module.LoadModule(db, name)
+	}
+
+	m, err := module.NewModule(cmd.Context(), afs, "go")
+	if err != nil {
+		return nil, err
+	}
+	return m, m.Load(db, name)
+}
+
+// scan checks the module providing m and each of its dependencies. For every
+// finding it works out whether updating the tool would fix it: the tool's own
+// module is fixed when the update reaches the fixed version, a dependency
+// when the go.mod of the update requires a fixed version or drops it.
+func scan(out io.Writer, vulns *Database, m *module.Module, offline bool) []Finding {
+	versions := map[string]string{m.ModuleRoot(): m.Version}
+	paths := []string{m.ModuleRoot()}
+	for _, d := range m.Dependencies {
+		if _, ok := versions[d.Name]; !ok {
+			versions[d.Name] = d.Version
+			paths = append(paths, d.Name)
+		}
+	}
+
+	update, err := m.KnownUpdate()
+	if err != nil || semver.Compare(update, m.Version) <= 0 {
+		update = ""
+	}
+
+	var (
+		findings []Finding
+		requires map[string]string
+		fetched  bool
+	)
+	for _, path := range paths {
+		for _, v := range vulns.Lookup(path, versions[path]) {
+			f := Finding{
+				Tool:        m.Name,
+				ToolVersion: m.Version,
+				Module:      path,
+				Version:     versions[path],
+				ID:          v.Entry.ID,
+				Aliases:     v.Entry.Aliases,
+				Summary:     v.Entry.Summary,
+				Fixed:       v.Fixed,
+				Update:      update,
+				UpdateFixes: fixNo,
+			}
+
+			switch {
+			case update == "" || v.Fixed == "":
+			case path == m.ModuleRoot():
+				f.UpdateFixes = fixedBy(update, v.Fixed)
+			case offline:
+				f.UpdateFixes = fixUnknown
+			default:
+				if !fetched {
+					fetched = true
+					if requires, err = m.Requirements(update); err != nil {
+						_, _ = fmt.Fprintf(out, "Warning: %v\n", err)
+					}
+				}
+				switch required, ok := requires[path]; {
+				case requires == nil:
+					f.UpdateFixes = fixUnknown
+				case !ok:
+					// The update no longer uses the module at all.
+					f.UpdateFixes = fixYes
+				default:
+					f.UpdateFixes = fixedBy(required, v.Fixed)
+				}
+			}
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+func fixedBy(version, fixed string) string {
+	if semver.Compare(canonical(version), canonical(fixed)) >= 0 {
+		return fixYes
+	}
+	return fixNo
+}
+
+func countTools(findings []Finding) int {
+	tools := make(map[string]struct{})
+	for _, f := range findings {
+		tools[f.Tool] = struct{}{}
+	}
+	return len(tools)
+}
+
+func orNone(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,144 @@
+package audit
+
+import (
+	"archive/zip"
+	"bytes"
+	"github.com/inovacc/goinstall/internal/module"
+	"github.com/spf13/afero"
+	"io"
+	"strings"
+	"testing"
+)
+
+const netEntry = `{
+  "id": "GO-2024-0001",
+  "summary": "Denial of service in golang.org/x/net",
+  "aliases": ["CVE-2024-0001"],
+  "affected": [{
+    "package": {"ecosystem": "Go", "name": "golang.org/x/net"},
+    "ranges": [{"type": "SEMVER", "events": [
+      {"introduced": "0"}, {"fixed": "0.23.0"},
+      {"introduced": "0.25.0"}, {"fixed": "0.25.2"}
+    ]}]
+  }]
+}`
+
+const toolEntry = `{
+  "id": "GO-2024-0002",
+  "summary": "Code injection in example.com/tool",
+  "affected": [{
+    "package": {"ecosystem": "Go", "name": "example.com/tool"},
+    "ranges": [{"type": "SEMVER", "events": [{"introduced": "1.0.0"}, {"last_affected": "1.1.0"}]}]
+  }]
+}`
+
+const withdrawnEntry = `{
+  "id": "GO-2024-0003",
+  "withdrawn": "2024-02-01T00:00:00Z",
+  "affected": [{"package": {"ecosystem": "Go", "name": "golang.org/x/net"}, "versions": ["0.24.0"]}]
+}`
+
+func testDatabase(t *testing.T) *Database {
+	fs := afero.NewMemMapFs()
+	for name, data := range map[string]string{
+		"osv/GO-2024-0001.json":   netEntry,
+		"osv/x/GO-2024-0002.json": toolEntry,
+		"osv/GO-2024-0003.json":   withdrawnEntry,
+		"osv/README.md":           "not an entry",
+		"osv/GO-2024-0004.json":   `{"id": "GO-2024-0004", "affected": {}}`,
+	} {
+		if err := afero.WriteFile(fs, name, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	db, err := LoadDatabase(fs, "osv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if skipped := db.Skipped(); len(skipped) != 1 || !strings.Contains(skipped[0].Error(), "GO-2024-0004.json") {
+		t.Fatalf("expected the malformed entry to be skipped, got %v", skipped)
+	}
+	return db
+}
+
+func TestDatabase_Lookup(t *testing.T) {
+	db := testDatabase(t)
+
+	tests := []struct {
+		path, version string
+		ids           int
+		fixed         string
+	}{
+		{"golang.org/x/net", "v0.20.0", 1, "v0.23.0"},
+		{"golang.org/x/net", "v0.23.0", 0, ""},
+		{"golang.org/x/net", "v0.24.0", 0, ""},
+		{"golang.org/x/net", "v0.25.1", 1, "v0.25.2"},
+		{"golang.org/x/net", "v0.0.0-20220101000000-abcdefabcdef", 1, "v0.23.0"},
+		{"example.com/tool", "v1.1.0", 1, ""},
+		{"example.com/tool", "v1.2.0", 0, ""},
+		{"example.com/other", "v1.0.0", 0, ""},
+	}
+
+	for _, tt := range tests {
+		vulns := db.Lookup(tt.path, tt.version)
+		if len(vulns) != tt.ids {
+			t.Fatalf("%s@%s: expected %d vulnerabilities but got %d", tt.path, tt.version, tt.ids, len(vulns))
+		}
+		if len(vulns) > 0 && vulns[0].Fixed != tt.fixed {
+			t.Fatalf("%s@%s: expected fixed %q but got %q", tt.path, tt.version, tt.fixed, vulns[0].Fixed)
+		}
+	}
+}
+
+func TestLoadDatabase_Zip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("GO-2024-0001.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte(netEntry)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "all.zip", buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := LoadDatabase(fs, "all.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Len() != 1 || len(db.Lookup("golang.org/x/net", "v0.1.0")) != 1 {
+		t.Fatalf("expected the zipped entry to be loaded")
+	}
+}
+
+func TestScan(t *testing.T) {
+	m := &module.Module{
+		Name:     "example.com/tool/cmd/tool",
+		Version:  "v1.1.0",
+		Versions: []string{"v1.3.0", "v1.1.0"},
+		Dependencies: []module.Dependency{
+			{Name: "example.com/tool", Version: "v1.1.0"},
+			{Name: "golang.org/x/net", Version: "v0.20.0"},
+		},
+	}
+
+	findings := scan(io.Discard, testDatabase(t), m, true)
+	if len(findings) != 2 {
+		t.Fatalf("expected 2 findings but got %+v", findings)
+	}
+
+	if f := findings[0]; f.Module != "example.com/tool" || f.Update != "v1.3.0" || f.UpdateFixes != fixNo {
+		t.Fatalf("unexpected tool finding %+v", f)
+	}
+	if f := findings[1]; f.Module != "golang.org/x/net" || f.Fixed != "v0.23.0" || f.UpdateFixes != fixUnknown {
+		t.Fatalf("unexpected dependency finding %+v", f)
+	}
+}
@@ -0,0 +1,282 @@
+package audit
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/afero"
+	"golang.org/x/mod/semver"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ecosystemGo is the OSV ecosystem of Go modules.
+const ecosystemGo = "Go"
+
+// Entry is an OSV vulnerability record, reduced to the fields the audit needs.
+type Entry struct {
+	ID        string     `json:"id"`
+	Summary   string     `json:"summary"`
+	Aliases   []string   `json:"aliases"`
+	Withdrawn string     `json:"withdrawn"`
+	Affected  []Affected `json:"affected"`
+}
+
+// Affected lists the affected versions of one package.
+type Affected struct {
+	Package  Package  `json:"package"`
+	Ranges   []Range  `json:"ranges"`
+	Versions []string `json:"versions"`
+}
+
+// Package identifies a package within an OSV ecosystem. For Go it is a
+// module path.
+type Package struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+}
+
+// Range is a list of events changing whether versions are affected.
+type Range struct {
+	Type   string  `json:"type"`
+	Events []Event `json:"events"`
+}
+
+// Event introduces, fixes or ends a vulnerability at a version.
+type Event struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+}
+
+// Database indexes OSV entries by affected module path.
+type Database struct {
+	entries map[string][]*Entry
+	// skipped holds why entries that could not be decoded were left out.
+	skipped []error
+}
+
+// LoadDatabase reads the OSV entries in path, either a directory of JSON files
+// (searched recursively) or a zip archive of them as published by osv.dev.
+// Entries that cannot be decoded are skipped and reported by Skipped.
+func LoadDatabase(fs afero.Fs, path string) (*Database, error) {
+	info, err := fs.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open vulnerability database: %w", err)
+	}
+
+	db := &Database{entries: make(map[string][]*Entry)}
+	if !info.IsDir() {
+		return db, db.loadZip(fs, path, info.Size())
+	}
+
+	err = afero.Walk(fs, path, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(name) != ".json" {
+			return nil
+		}
+
+		data, err := afero.ReadFile(fs, name)
+		if err != nil {
+			return err
+		}
+		db.add(name, data)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+func (db *Database) loadZip(fs afero.Fs, path string, size int64) error {
+	f, err := fs.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open vulnerability database: %w", err)
+	}
+	defer func(f afero.File) {
+		_ = f.Close()
+	}(f)
+
+	zr, err := zip.NewReader(f, size)
+	if err != nil {
+		return fmt.Errorf("failed to read vulnerability database %s: %w", path, err)
+	}
+
+	for _, zf := range zr.File {
+		if zf.FileInfo().IsDir() || filepath.Ext(zf.Name) != ".json" {
+			continue
+		}
+
+		data, err := readZipFile(zf)
+		if err != nil {
+			return err
+		}
+		db.add(zf.Name, data)
+	}
+	return nil
+}
+
+func readZipFile(zf *zip.File) ([]byte, error) {
+	rc, err := zf.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer func(rc io.ReadCloser) {
+		_ = rc.Close()
+	}(rc)
+	return io.ReadAll(rc)
+}
+
+func (db *Database) add(name string, data []byte) {
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		db.skipped = append(db.skipped, fmt.Errorf("skipped %s: %w", name, err))
+		return
+	}
+	if e.Withdrawn != "" {
+		return
+	}
+
+	for _, a := range e.Affected {
+		if a.Package.Ecosystem != ecosystemGo {
+			continue
+		}
+		if !slices.Contains(db.entries[a.Package.Name], &e) {
+			db.entries[a.Package.Name] = append(db.entries[a.Package.Name], &e)
+		}
+	}
+}
+
+// Len returns the number of modules with known vulnerabilities.
+func (db *Database) Len() int {
+	return len(db.entries)
+}
+
+// Skipped returns why the entries that could not be decoded were left out.
+func (db *Database) Skipped() []error {
+	return db.skipped
+}
+
+// Vulnerability is an entry affecting a module version, with the lowest
+// version fixing it. Fixed is empty when no fix has been released.
+type Vulnerability struct {
+	Entry *Entry
+	Fixed string
+}
+
+// Lookup returns the vulnerabilities affecting path at version.
+func (db *Database) Lookup(path, version string) []Vulnerability {
+	var vulns []Vulnerability
+	for _, e := range db.entries[path] {
+		for _, a := range e.Affected {
+			if a.Package.Ecosystem != ecosystemGo || a.Package.Name != path {
+				continue
+			}
+			if fixed, ok := a.affects(version); ok {
+				vulns = append(vulns, Vulnerability{Entry: e, Fixed: fixed})
+				break
+			}
+		}
+	}
+	return vulns
+}
+
+// affects reports whether version is affected and the lowest fixed version
+// above it.
+func (a Affected) affects(version string) (string, bool) {
+	version = canonical(version)
+	if !semver.IsValid(version) {
+		return "", false
+	}
+
+	affected := slices.ContainsFunc(a.Versions, func(v string) bool {
+		return canonical(v) == version
+	})
+
+	var fixed string
+	for _, r := range a.Ranges {
+		if r.Type != "SEMVER" && r.Type != "ECOSYSTEM" {
+			continue
+		}
+
+		in, fix := r.affects(version)
+		if !in {
+			continue
+		}
+		affected = true
+		if fix != "" && (fixed == "" || semver.Compare(fix, fixed) < 0) {
+			fixed = fix
+		}
+	}
+	return fixed, affected
+}
+
+// affects evaluates the events of r in version order, as described by the
+// OSV schema, and returns the fixed version ending the affected interval.
+func (r Range) affects(version string) (bool, string) {
+	type point struct {
+		version string
+		kind    int
+	}
+	const (
+		introduced = iota
+		fixed
+		lastAffected
+	)
+
+	var points []point
+	for _, e := range r.Events {
+		switch {
+		case e.Introduced != "":
+			v := canonical(e.Introduced)
+			if e.Introduced == "0" {
+				v = ""
+			}
+			points = append(points, point{v, introduced})
+		case e.Fixed != "":
+			points = append(points, point{canonical(e.Fixed), fixed})
+		case e.LastAffected != "":
+			points = append(points, point{canonical(e.LastAffected), lastAffected})
+		}
+	}
+	slices.SortStableFunc(points, func(a, b point) int {
+		return semver.Compare(a.version, b.version)
+	})
+
+	affected := false
+	for _, p := range points {
+		switch p.kind {
+		case introduced:
+			if semver.Compare(version, p.version) >= 0 {
+				affected = true
+			}
+		case fixed:
+			if semver.Compare(version, p.version) >= 0 {
+				affected = false
+			} else if affected {
+				return true, p.version
+			}
+		case lastAffected:
+			if semver.Compare(version, p.version) > 0 {
+				affected = false
+			} else if affected {
+				return true, ""
+			}
+		}
+	}
+	return affected, ""
+}
+
+// canonical adds the v prefix OSV leaves out of Go versions.
+func canonical(version string) string {
+	if version == "" || strings.HasPrefix(version, "v") {
+		return version
+	}
+	return "v" + version
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"github.com/spf13/afero"
 	"github.com/spf13/viper"
 	_ "modernc.org/sqlite"
@@ -41,6 +42,9 @@ func NewDatabase(ctx context.Context, afs afero.Fs) (*Database, error) {
 		return nil, err
 	}
 
+	// SQLite allows a single writer; serialize access instead of failing with SQLITE_BUSY.
+	db.SetMaxOpenConns(1)
+
 	if err := db.PingContext(ctx); err != nil {
 		return nil, err
 	}
@@ -62,6 +66,36 @@ func (d *Database) Begin() (*sql.Tx, error) {
 	return d.db.Begin()
 }
 
+func (d *Database) Exec(query string, args ...any) (sql.Result, error) {
+	return d.db.Exec(query, args...)
+}
+
+func (d *Database) Query(query string, args ...any) (*sql.Rows, error) {
+	return d.db.Query(query, args...)
+}
+
+func (d *Database) QueryRow(query string, args ...any) *sql.Row {
+	return d.db.QueryRow(query, args...)
+}
+
+// columns lists the columns added to existing tables after their creation.
+// They are added on open when a database predates them.
+var columns = []struct {
+	table string
+	name  string
+	decl  string
+}{
+	{"modules", "query", "TEXT"},
+	{"modules", "go_version", "TEXT"},
+	{"modules", "binary", "TEXT"},
+	{"modules", "size", "INTEGER"},
+	{"modules", "tags", "TEXT"},
+	{"modules", "alias", "TEXT"},
+	{"modules", "license", "TEXT"},
+	{"modules", "graph", "TEXT"},
+	{"dependencies", "license", "TEXT"},
+}
+
 func (d *Database) setupSchema() error {
 	schema := []string{
 		`CREATE TABLE IF NOT EXISTS modules (
@@ -81,11 +115,59 @@ func (d *Database) setupSchema() error {
 			FOREIGN KEY(module_name) REFERENCES modules(name) ON DELETE CASCADE,
 			PRIMARY KEY(module_name, dep_name)
 		);`,
+		`CREATE TABLE IF NOT EXISTS dependency_edges (
+			module_name TEXT NOT NULL,
+			from_name TEXT NOT NULL,
+			from_version TEXT,
+			to_name TEXT NOT NULL,
+			to_version TEXT,
+			PRIMARY KEY(module_name, from_name, to_name)
+		);`,
+		`CREATE TABLE IF NOT EXISTS module_versions (
+			module_name TEXT NOT NULL,
+			version TEXT NOT NULL,
+			seen TIMESTAMP,
+			PRIMARY KEY(module_name, version)
+		);`,
+		`CREATE TABLE IF NOT EXISTS policies (
+			module_name TEXT NOT NULL PRIMARY KEY,
+			policy TEXT NOT NULL
+		);`,
+		`CREATE TABLE IF NOT EXISTS shims (
+			module_name TEXT NOT NULL PRIMARY KEY
+		);`,
+		`CREATE TABLE IF NOT EXISTS events (
+			module_name TEXT NOT NULL,
+			version TEXT,
+			action TEXT NOT NULL,
+			detail TEXT,
+			time TIMESTAMP
+		);`,
 	}
 	for _, stmt := range schema {
 		if _, err := d.db.Exec(stmt); err != nil {
 			return err
 		}
 	}
+
+	for _, c := range columns {
+		if err := d.addColumn(c.table, c.name, c.decl); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+func (d *Database) addColumn(table, column, decl string) error {
+	var exists bool
+	query := `SELECT COUNT(*) > 0 FROM pragma_table_info(?) WHERE name = ?`
+	if err := d.db.QueryRow(query, table, column).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	_, err := d.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, decl))
+	return err
+}
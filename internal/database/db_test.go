@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"github.com/spf13/afero"
 	"github.com/spf13/viper"
 	"path/filepath"
@@ -26,3 +27,37 @@ func TestNewDatabase(t *testing.T) {
 		t.Fatal("db is nil")
 	}
 }
+
+func TestNewDatabase_AddsColumns(t *testing.T) {
+	afs := afero.NewOsFs()
+	dbPath := filepath.Join(t.TempDir(), "modules.db")
+	viper.Set("installPath", dbPath)
+
+	// A database created before the query column existed.
+	legacy, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := legacy.Exec(`CREATE TABLE modules (name TEXT NOT NULL, version TEXT NOT NULL, versions TEXT, dependencies TEXT, hash TEXT, time TIMESTAMP, PRIMARY KEY(name, version));`); err != nil {
+		t.Fatal(err)
+	}
+	_ = legacy.Close()
+
+	for range 2 {
+		db, err := NewDatabase(context.TODO(), afs)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, c := range columns {
+			var exists bool
+			if err := db.QueryRow(`SELECT COUNT(*) > 0 FROM pragma_table_info(?) WHERE name = ?`, c.table, c.name).Scan(&exists); err != nil {
+				t.Fatal(err)
+			}
+			if !exists {
+				t.Fatalf("column %s.%s was not added", c.table, c.name)
+			}
+		}
+		_ = db.Close()
+	}
+}
@@ -0,0 +1,208 @@
+package goproxy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang.org/x/mod/module"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const DefaultProxy = "https://proxy.golang.org,direct"
+
+var (
+	// ErrDirect is returned when the module has to be fetched from its
+	// version control system, either because GOPROXY reached "direct" or
+	// because the module matches GOPRIVATE/GONOPROXY.
+	ErrDirect = errors.New("module must be fetched directly")
+	// ErrDisabled is returned when GOPROXY reached "off".
+	ErrDisabled = errors.New("module lookup disabled by GOPROXY=off")
+	// ErrNotFound is returned when every proxy answered 404 or 410.
+	ErrNotFound = errors.New("module not found")
+)
+
+// Info is the response of the .info and @latest endpoints.
+type Info struct {
+	Version string    `json:"Version"`
+	Time    time.Time `json:"Time"`
+}
+
+type entry struct {
+	url string
+	// anyError reports whether the next entry is tried after any error,
+	// which is the case when this entry is followed by a pipe. After a comma
+	// only 404 and 410 responses fall through.
+	anyError bool
+}
+
+// Client implements the module proxy protocol for a GOPROXY list.
+type Client struct {
+	entries []entry
+	noProxy string
+	http    *http.Client
+}
+
+// New returns a client for the GOPROXY list goproxy. Modules matching the
+// GONOPROXY patterns in noProxy are never looked up through a proxy.
+func New(goproxy, noProxy string) *Client {
+	if goproxy == "" {
+		goproxy = DefaultProxy
+	}
+
+	var entries []entry
+	for goproxy != "" {
+		item, sep := goproxy, byte(0)
+		if i := strings.IndexAny(goproxy, ",|"); i >= 0 {
+			item, sep, goproxy = goproxy[:i], goproxy[i], goproxy[i+1:]
+		} else {
+			goproxy = ""
+		}
+
+		if item = strings.TrimSpace(item); item != "" {
+			entries = append(entries, entry{url: strings.TrimSuffix(item, "/"), anyError: sep == '|'})
+		}
+	}
+
+	return &Client{
+		entries: entries,
+		noProxy: noProxy,
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// List returns the tagged versions of path known to the proxy.
+func (c *Client) List(ctx context.Context, path string) ([]string, error) {
+	data, err := c.fetch(ctx, path, "@v/list")
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		if fields := strings.Fields(sc.Text()); len(fields) > 0 {
+			versions = append(versions, fields[0])
+		}
+	}
+	return versions, sc.Err()
+}
+
+// Info returns the canonical version and commit time of path at version.
+func (c *Client) Info(ctx context.Context, path, version string) (*Info, error) {
+	escaped, err := module.EscapeVersion(version)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := c.fetch(ctx, path, "@v/"+escaped+".info")
+	if err != nil {
+		return nil, err
+	}
+	return decodeInfo(data)
+}
+
+// Mod returns the go.mod file of path at version.
+func (c *Client) Mod(ctx context.Context, path, version string) ([]byte, error) {
+	escaped, err := module.EscapeVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	return c.fetch(ctx, path, "@v/"+escaped+".mod")
+}
+
+// Latest returns the version the proxy considers the latest for path, used
+// when no tagged versions exist.
+func (c *Client) Latest(ctx context.Context, path string) (*Info, error) {
+	data, err := c.fetch(ctx, path, "@latest")
+	if err != nil {
+		return nil, err
+	}
+	return decodeInfo(data)
+}
+
+// fetch requests the endpoint of path from each proxy in turn, following the
+// GOPROXY fallback rules.
+func (c *Client) fetch(ctx context.Context, path, endpoint string) ([]byte, error) {
+	if c.noProxy != "" && module.MatchPrefixPatterns(c.noProxy, path) {
+		return nil, ErrDirect
+	}
+
+	escaped, err := module.EscapePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	lastErr := error(ErrNotFound)
+	for _, e := range c.entries {
+		switch e.url {
+		case "direct":
+			return nil, ErrDirect
+		case "off":
+			return nil, ErrDisabled
+		}
+
+		data, err := c.get(ctx, e.url, escaped+"/"+endpoint)
+		if err == nil {
+			return data, nil
+		}
+		lastErr = err
+
+		if ctx.Err() != nil || !(e.anyError || errors.Is(err, ErrNotFound)) {
+			return nil, err
+		}
+	}
+	return nil, lastErr
+}
+
+func (c *Client) get(ctx context.Context, base, rel string) ([]byte, error) {
+	if strings.HasPrefix(base, "file://") {
+		u, err := url.Parse(base)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy %q: %w", base, err)
+		}
+
+		data, err := os.ReadFile(filepath.Join(filepath.FromSlash(u.Path), filepath.FromSlash(rel)))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%s/%s: %w", base, rel, ErrNotFound)
+		}
+		return data, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"/"+rel, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func(body io.ReadCloser) {
+		_ = body.Close()
+	}(resp.Body)
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return nil, fmt.Errorf("%s: %s: %w", req.URL, resp.Status, ErrNotFound)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("%s: %s", req.URL, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func decodeInfo(data []byte) (*Info, error) {
+	var info Info
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("decoding info response failed: %w", err)
+	}
+	return &info, nil
+}
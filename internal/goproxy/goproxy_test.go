@@ -0,0 +1,148 @@
+package goproxy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestClient_HTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/github.com/!burnt!sushi/toml/@v/list":
+			_, _ = w.Write([]byte("v1.1.0\nv1.2.0\n\n"))
+		case "/github.com/!burnt!sushi/toml/@v/v1.2.0.info":
+			_, _ = w.Write([]byte(`{"Version":"v1.2.0","Time":"2024-01-02T03:04:05Z"}`))
+		case "/github.com/!burnt!sushi/toml/@v/v1.2.0.mod":
+			_, _ = w.Write([]byte("module github.com/BurntSushi/toml\n"))
+		case "/github.com/!burnt!sushi/toml/@latest":
+			_, _ = w.Write([]byte(`{"Version":"v1.2.0"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, "")
+	ctx := context.TODO()
+
+	versions, err := c.List(ctx, "github.com/BurntSushi/toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(versions, []string{"v1.1.0", "v1.2.0"}) {
+		t.Fatalf("unexpected versions %v", versions)
+	}
+
+	info, err := c.Info(ctx, "github.com/BurntSushi/toml", "v1.2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Version != "v1.2.0" || info.Time.Year() != 2024 {
+		t.Fatalf("unexpected info %+v", info)
+	}
+
+	mod, err := c.Mod(ctx, "github.com/BurntSushi/toml", "v1.2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(mod) != "module github.com/BurntSushi/toml\n" {
+		t.Fatalf("unexpected go.mod %q", mod)
+	}
+
+	if latest, err := c.Latest(ctx, "github.com/BurntSushi/toml"); err != nil || latest.Version != "v1.2.0" {
+		t.Fatalf("unexpected latest %+v, %v", latest, err)
+	}
+
+	if _, err := c.List(ctx, "example.com/missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound but got %v", err)
+	}
+}
+
+func TestClient_FileProxy(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "example.com", "tool", "@v"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "example.com", "tool", "@v", "list"), []byte("v0.1.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := New("file://"+filepath.ToSlash(dir), "")
+	versions, err := c.List(context.TODO(), "example.com/tool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(versions, []string{"v0.1.0"}) {
+		t.Fatalf("unexpected versions %v", versions)
+	}
+}
+
+func TestClient_Fallback(t *testing.T) {
+	var hits []string
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits = append(hits, "failing")
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	missing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits = append(hits, "missing")
+		http.NotFound(w, r)
+	}))
+	defer missing.Close()
+
+	working := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits = append(hits, "working")
+		_, _ = w.Write([]byte("v1.0.0\n"))
+	}))
+	defer working.Close()
+
+	ctx := context.TODO()
+	tests := []struct {
+		name    string
+		goproxy string
+		want    error
+		hits    []string
+	}{
+		{"comma falls back on 404", missing.URL + "," + working.URL, nil, []string{"missing", "working"}},
+		{"comma stops on other errors", failing.URL + "," + working.URL, errors.New("500"), []string{"failing"}},
+		{"pipe falls back on any error", failing.URL + "|" + working.URL, nil, []string{"failing", "working"}},
+		{"direct", missing.URL + ",direct", ErrDirect, []string{"missing"}},
+		{"off", "off", ErrDisabled, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits = nil
+			_, err := New(tt.goproxy, "").List(ctx, "example.com/tool")
+
+			switch {
+			case tt.want == nil && err != nil:
+				t.Fatalf("unexpected error %v", err)
+			case tt.want != nil && err == nil:
+				t.Fatalf("expected error %v", tt.want)
+			case errors.Is(tt.want, ErrDirect) || errors.Is(tt.want, ErrDisabled):
+				if !errors.Is(err, tt.want) {
+					t.Fatalf("expected %v but got %v", tt.want, err)
+				}
+			}
+
+			if !slices.Equal(hits, tt.hits) {
+				t.Fatalf("expected hits %v but got %v", tt.hits, hits)
+			}
+		})
+	}
+}
+
+func TestClient_NoProxy(t *testing.T) {
+	c := New("https://proxy.invalid", "*.corp.example.com,github.com/private")
+	if _, err := c.List(context.TODO(), "github.com/private/tool"); !errors.Is(err, ErrDirect) {
+		t.Fatalf("expected ErrDirect but got %v", err)
+	}
+}
@@ -0,0 +1,339 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/inovacc/goinstall/internal/database"
+	"github.com/inovacc/goinstall/internal/module"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"io"
+	"slices"
+	"strings"
+)
+
+const (
+	FormatDOT     = "dot"
+	FormatMermaid = "mermaid"
+	FormatJSON    = "json"
+)
+
+var afs afero.Fs
+
+// Node is a module at its selected version.
+type Node struct {
+	ID      string `json:"id"`
+	Path    string `json:"path"`
+	Version string `json:"version"`
+}
+
+// Link is a requirement between two nodes and the version it requires.
+type Link struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Requires string `json:"requires"`
+}
+
+// DepGraph is the dependency graph of one or more installed tools.
+type DepGraph struct {
+	Roots []string
+	Nodes map[string]Node
+	Links []Link
+}
+
+func Graph(cmd *cobra.Command, args []string) error {
+	afs = afero.NewOsFs()
+
+	db, err := database.NewDatabase(cmd.Context(), afs)
+	if err != nil {
+		return err
+	}
+	defer func(db *database.Database) {
+		cobra.CheckErr(db.Close())
+	}(db)
+
+	format, _ := cmd.Flags().GetString("format")
+	depth, _ := cmd.Flags().GetInt("depth")
+	prefixes, _ := cmd.Flags().GetStringSlice("prefix")
+
+	mods, err := Load(db, args)
+	if err != nil {
+		return err
+	}
+
+	g := New(mods).Limit(depth).Filter(prefixes)
+	return g.Render(cmd.OutOrStdout(), format)
+}
+
+// Load returns the named tool, or every installed tool when no name
+// is given, with its recorded graph.
+func Load(db *database.Database, args []string) ([]module.Module, error) {
+	if len(args) > 0 {
+		m, err := module.LoadModule(db, module.ParseName(args[0]))
+		if err != nil {
+			return nil, err
+		}
+		return []module.Module{*m}, nil
+	}
+
+	installed, err := module.LoadInstalled(db)
+	if err != nil {
+		return nil, err
+	}
+	for i := range installed {
+		if installed[i].Graph, err = module.LoadGraph(db, installed[i].Name); err != nil {
+			return nil, err
+		}
+	}
+	return installed, nil
+}
+
+// New builds the combined graph of mods. Modules are identified by path and
+// selected version, so tools sharing a dependency at the same version share
+// its node.
+func New(mods []module.Module) *DepGraph {
+	g := &DepGraph{Nodes: make(map[string]Node)}
+	seen := make(map[Link]struct{})
+
+	for _, m := range mods {
+		selected := map[string]string{m.Name: m.Version}
+		for _, d := range m.Dependencies {
+			selected[d.Name] = d.Version
+		}
+
+		root := g.add(m.Name, m.Version)
+		if !slices.Contains(g.Roots, root) {
+			g.Roots = append(g.Roots, root)
+		}
+
+		for _, e := range m.Graph {
+			fromVersion, ok := selected[e.From]
+			if !ok {
+				fromVersion = e.FromVersion
+			}
+			toVersion, ok := selected[e.To]
+			if !ok {
+				toVersion = e.Version
+			}
+
+			link := Link{From: g.add(e.From, fromVersion), To: g.add(e.To, toVersion), Requires: e.Version}
+			if _, ok := seen[link]; ok {
+				continue
+			}
+			seen[link] = struct{}{}
+			g.Links = append(g.Links, link)
+		}
+	}
+	return g
+}
+
+func (g *DepGraph) add(path, version string) string {
+	id := path
+	if version != "" {
+		id = fmt.Sprintf("%s@%s", path, version)
+	}
+	if _, ok := g.Nodes[id]; !ok {
+		g.Nodes[id] = Node{ID: id, Path: path, Version: version}
+	}
+	return id
+}
+
+// Limit keeps the nodes at most depth requirements away from a root. A
+// depth below one keeps the whole graph.
+func (g *DepGraph) Limit(depth int) *DepGraph {
+	if depth < 1 {
+		return g
+	}
+
+	adjacency := g.adjacency()
+	distance := make(map[string]int)
+	queue := slices.Clone(g.Roots)
+	for _, r := range g.Roots {
+		distance[r] = 0
+	}
+
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if distance[id] == depth {
+			continue
+		}
+		for _, l := range adjacency[id] {
+			if _, ok := distance[l.To]; !ok {
+				distance[l.To] = distance[id] + 1
+				queue = append(queue, l.To)
+			}
+		}
+	}
+
+	return g.keep(func(id string) bool {
+		_, ok := distance[id]
+		return ok
+	})
+}
+
+// Filter keeps the nodes whose path starts with one of prefixes together
+// with every node on a path from a root to them. No prefixes keeps the
+// whole graph.
+func (g *DepGraph) Filter(prefixes []string) *DepGraph {
+	if len(prefixes) == 0 {
+		return g
+	}
+
+	reverse := make(map[string][]string)
+	for _, l := range g.Links {
+		reverse[l.To] = append(reverse[l.To], l.From)
+	}
+
+	keep := make(map[string]bool)
+	var queue []string
+	for id, n := range g.Nodes {
+		for _, p := range prefixes {
+			if strings.HasPrefix(n.Path, p) {
+				keep[id] = true
+				queue = append(queue, id)
+				break
+			}
+		}
+	}
+
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, from := range reverse[id] {
+			if !keep[from] {
+				keep[from] = true
+				queue = append(queue, from)
+			}
+		}
+	}
+
+	return g.keep(func(id string) bool { return keep[id] })
+}
+
+func (g *DepGraph) keep(fn func(id string) bool) *DepGraph {
+	kept := &DepGraph{Nodes: make(map[string]Node)}
+	for _, r := range g.Roots {
+		if fn(r) {
+			kept.Roots = append(kept.Roots, r)
+		}
+	}
+	for id, n := range g.Nodes {
+		if fn(id) {
+			kept.Nodes[id] = n
+		}
+	}
+	for _, l := range g.Links {
+		if fn(l.From) && fn(l.To) {
+			kept.Links = append(kept.Links, l)
+		}
+	}
+	return kept
+}
+
+func (g *DepGraph) adjacency() map[string][]Link {
+	adjacency := make(map[string][]Link)
+	for _, l := range g.Links {
+		adjacency[l.From] = append(adjacency[l.From], l)
+	}
+	return adjacency
+}
+
+// sortedIDs returns the node ids with the roots first, for stable output.
+func (g *DepGraph) sortedIDs() []string {
+	ids := make([]string, 0, len(g.Nodes))
+	for id := range g.Nodes {
+		if !slices.Contains(g.Roots, id) {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return append(slices.Clone(g.Roots), ids...)
+}
+
+// label returns the required version of l when it differs from the version
+// selected for its target, which is the interesting case when tracing.
+func (g *DepGraph) label(l Link) string {
+	if l.Requires == g.Nodes[l.To].Version {
+		return ""
+	}
+	return l.Requires
+}
+
+// Render writes the graph in format: dot, mermaid or json.
+func (g *DepGraph) Render(w io.Writer, format string) error {
+	switch format {
+	case FormatDOT, "":
+		return g.renderDOT(w)
+	case FormatMermaid:
+		return g.renderMermaid(w)
+	case FormatJSON:
+		return g.renderJSON(w)
+	default:
+		return fmt.Errorf("unknown graph format %q (want %s, %s or %s)", format, FormatDOT, FormatMermaid, FormatJSON)
+	}
+}
+
+func (g *DepGraph) renderDOT(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph dependencies {\n\trankdir=LR;\n\tnode [shape=box];\n")
+	for _, id := range g.Roots {
+		_, _ = fmt.Fprintf(&b, "\t%q [style=bold];\n", id)
+	}
+	for _, l := range g.Links {
+		if label := g.label(l); label != "" {
+			_, _ = fmt.Fprintf(&b, "\t%q -> %q [label=%q];\n", l.From, l.To, label)
+			continue
+		}
+		_, _ = fmt.Fprintf(&b, "\t%q -> %q;\n", l.From, l.To)
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (g *DepGraph) renderMermaid(w io.Writer) error {
+	ids := make(map[string]string, len(g.Nodes))
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	for i, id := range g.sortedIDs() {
+		ids[id] = fmt.Sprintf("n%d", i)
+		_, _ = fmt.Fprintf(&b, "\tn%d[\"%s\"]\n", i, id)
+	}
+	for _, l := range g.Links {
+		if label := g.label(l); label != "" {
+			_, _ = fmt.Fprintf(&b, "\t%s -->|%s| %s\n", ids[l.From], label, ids[l.To])
+			continue
+		}
+		_, _ = fmt.Fprintf(&b, "\t%s --> %s\n", ids[l.From], ids[l.To])
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+type jsonEdge struct {
+	To       string `json:"to"`
+	Requires string `json:"requires"`
+}
+
+func (g *DepGraph) renderJSON(w io.Writer) error {
+	nodes := make([]Node, 0, len(g.Nodes))
+	adjacency := make(map[string][]jsonEdge, len(g.Nodes))
+	for _, id := range g.sortedIDs() {
+		nodes = append(nodes, g.Nodes[id])
+		adjacency[id] = []jsonEdge{}
+	}
+	for _, l := range g.Links {
+		adjacency[l.From] = append(adjacency[l.From], jsonEdge{To: l.To, Requires: l.Requires})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Roots     []string              `json:"roots"`
+		Nodes     []Node                `json:"nodes"`
+		Adjacency map[string][]jsonEdge `json:"adjacency"`
+	}{Roots: g.Roots, Nodes: nodes, Adjacency: adjacency})
+}
@@ -0,0 +1,136 @@
+package graph
+
+import (
+	"bytes"
+	"context"
+	"github.com/inovacc/goinstall/internal/database"
+	"github.com/inovacc/goinstall/internal/module"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func testModules() []module.Module {
+	return []module.Module{
+		{
+			Name:    "example.com/tool",
+			Version: "v1.0.0",
+			Dependencies: []module.Dependency{
+				{Name: "golang.org/x/tools", Version: "v0.36.0"},
+				{Name: "golang.org/x/net", Version: "v0.43.0"},
+				{Name: "golang.org/x/sys", Version: "v0.35.0"},
+			},
+			Graph: []module.Edge{
+				{From: "example.com/tool", FromVersion: "v1.0.0", To: "golang.org/x/tools", Version: "v0.36.0"},
+				{From: "golang.org/x/tools", FromVersion: "v0.36.0", To: "golang.org/x/net", Version: "v0.40.0"},
+				{From: "golang.org/x/net", FromVersion: "v0.43.0", To: "golang.org/x/sys", Version: "v0.35.0"},
+			},
+		},
+	}
+}
+
+func TestDepGraph_Render(t *testing.T) {
+	var dot bytes.Buffer
+	if err := New(testModules()).Render(&dot, FormatDOT); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`"example.com/tool@v1.0.0" [style=bold];`,
+		`"golang.org/x/tools@v0.36.0" -> "golang.org/x/net@v0.43.0" [label="v0.40.0"];`,
+		`"golang.org/x/net@v0.43.0" -> "golang.org/x/sys@v0.35.0";`,
+	} {
+		if !strings.Contains(dot.String(), want) {
+			t.Errorf("expected dot output to contain %s, got:\n%s", want, dot.String())
+		}
+	}
+
+	var mermaid bytes.Buffer
+	if err := New(testModules()).Render(&mermaid, FormatMermaid); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(mermaid.String(), "graph LR\n\tn0[\"example.com/tool@v1.0.0\"]") {
+		t.Errorf("unexpected mermaid output:\n%s", mermaid.String())
+	}
+
+	if err := New(testModules()).Render(&bytes.Buffer{}, "svg"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestDepGraph_Limit(t *testing.T) {
+	g := New(testModules()).Limit(2)
+	if len(g.Nodes) != 3 {
+		t.Fatalf("expected 3 nodes within depth 2 but got %d", len(g.Nodes))
+	}
+	if _, ok := g.Nodes["golang.org/x/sys@v0.35.0"]; ok {
+		t.Fatal("expected golang.org/x/sys to be beyond depth 2")
+	}
+}
+
+func TestDepGraph_Filter(t *testing.T) {
+	g := New(testModules()).Filter([]string{"golang.org/x/net"})
+	if len(g.Nodes) != 3 || len(g.Links) != 2 {
+		t.Fatalf("expected the path to golang.org/x/net, got %v and %v", g.Nodes, g.Links)
+	}
+	if _, ok := g.Nodes["golang.org/x/sys@v0.35.0"]; ok {
+		t.Fatal("expected golang.org/x/sys to be filtered out")
+	}
+}
+
+func TestShortestPath(t *testing.T) {
+	path := shortestPath(testModules()[0].Graph, "example.com/tool", "golang.org/x/sys")
+	want := []string{"example.com/tool", "golang.org/x/tools", "golang.org/x/net", "golang.org/x/sys"}
+	if !slices.Equal(path, want) {
+		t.Fatalf("expected %v but got %v", want, path)
+	}
+
+	if path := shortestPath(nil, "example.com/tool", "golang.org/x/sys"); len(path) != 2 {
+		t.Fatalf("expected both ends without a graph, got %v", path)
+	}
+}
+
+func TestFindUsages(t *testing.T) {
+	viper.Set("installPath", filepath.Join(t.TempDir(), "modules.db"))
+
+	db, err := database.NewDatabase(context.TODO(), afero.NewOsFs())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(db *database.Database) {
+		_ = db.Close()
+	}(db)
+
+	mods := testModules()
+	mods[0].Dependencies = append(mods[0].Dependencies,
+		module.Dependency{Name: "golang.org/x/sync_v2", Version: "v2.0.0"},
+		module.Dependency{Name: "GOLANG.ORG/X/NET", Version: "v0.1.0"},
+	)
+	for i := range mods {
+		if err := mods[i].Report(db); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for target, want := range map[string]string{
+		"golang.org/x/net":              "golang.org/x/net",
+		"golang.org/x/net/http2":        "golang.org/x/net",
+		"golang.org/x/sync1v2/errgroup": "",
+		"golang.org/x/networking":       "",
+	} {
+		usages, err := findUsages(db, target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, u := range usages {
+			got = append(got, u.Module)
+		}
+		if strings.Join(got, " ") != want {
+			t.Errorf("findUsages(%s) = %v, want %s", target, got, want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package graph
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/inovacc/goinstall/internal/database"
+	"github.com/inovacc/goinstall/internal/module"
+	"github.com/inovacc/goinstall/internal/printer"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// Usage is an installed tool that depends on the queried module.
+type Usage struct {
+	Tool    string   `json:"tool"`
+	Module  string   `json:"module"`
+	Version string   `json:"version"`
+	Path    []string `json:"path"`
+}
+
+// Why lists the installed tools that depend on a module, the version each
+// of them selects and the requirement path from the tool down to it.
+func Why(cmd *cobra.Command, args []string) error {
+	afs = afero.NewOsFs()
+
+	db, err := database.NewDatabase(cmd.Context(), afs)
+	if err != nil {
+		return err
+	}
+	defer func(db *database.Database) {
+		cobra.CheckErr(db.Close())
+	}(db)
+
+	format, _ := cmd.Flags().GetString("output")
+	target := module.ParseName(args[0])
+
+	usages, err := findUsages(db, target)
+	if err != nil {
+		return err
+	}
+
+	if len(usages) == 0 && format == printer.FormatTable {
+		cmd.Printf("No installed module depends on %s\n", target)
+		return nil
+	}
+
+	return printer.Print(cmd.OutOrStdout(), format, usages, func(tw *tabwriter.Writer) {
+		_, _ = fmt.Fprintln(tw, "TOOL\tMODULE\tVERSION\tPATH")
+		for _, u := range usages {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", u.Tool, u.Module, u.Version, strings.Join(u.Path, " -> "))
+		}
+	})
+}
+
+// findUsages queries the dependencies of every tracked module for target. A
+// package path matches the module that provides it.
+func findUsages(db *database.Database, target string) ([]Usage, error) {
+	rows, err := db.Query(`
+		SELECT module_name, dep_name, dep_version
+		FROM dependencies
+		WHERE dep_name = ? OR substr(?, 1, length(dep_name) + 1) = dep_name || '/'
+		ORDER BY module_name, dep_name
+		`, target, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dependencies: %w", err)
+	}
+
+	var usages []Usage
+	err = func(rows *sql.Rows) error {
+		defer func() {
+			_ = rows.Close()
+		}()
+		for rows.Next() {
+			var (
+				u       Usage
+				version sql.NullString
+			)
+			if err := rows.Scan(&u.Tool, &u.Module, &version); err != nil {
+				return err
+			}
+			u.Version = version.String
+			usages = append(usages, u)
+		}
+		return rows.Err()
+	}(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range usages {
+		edges, err := module.LoadGraph(db, usages[i].Tool)
+		if err != nil {
+			return nil, err
+		}
+		usages[i].Path = shortestPath(edges, usages[i].Tool, usages[i].Module)
+	}
+	return usages, nil
+}
+
+// shortestPath returns the module paths on the shortest requirement path from
+// the tool to target, or just both ends when the graph was not recorded.
+func shortestPath(edges []module.Edge, tool, target string) []string {
+	adjacency := make(map[string][]string)
+	for _, e := range edges {
+		adjacency[e.From] = append(adjacency[e.From], e.To)
+	}
+
+	parent := map[string]string{tool: ""}
+	queue := []string{tool}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if current == target {
+			var path []string
+			for n := target; n != ""; n = parent[n] {
+				path = append(path, n)
+			}
+			slices.Reverse(path)
+			return path
+		}
+
+		for _, next := range adjacency[current] {
+			if _, ok := parent[next]; !ok {
+				parent[next] = current
+				queue = append(queue, next)
+			}
+		}
+	}
+	return []string{tool, target}
+}
@@ -0,0 +1,134 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"github.com/inovacc/goinstall/internal/database"
+	"github.com/inovacc/goinstall/internal/module"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"io"
+	"path/filepath"
+	"text/tabwriter"
+)
+
+const (
+	statusAdopted = "adopted"
+	statusTracked = "tracked"
+	statusSkipped = "skipped"
+)
+
+type adoptResult struct {
+	Binary  string
+	Name    string
+	Version string
+	Status  string
+	Reason  string
+}
+
+// Adopt records the Go binaries in GOBIN that are not tracked yet, using the
+// build information embedded in each of them. Adopted modules can be reported
+// on and updated like installed ones.
+func Adopt(cmd *cobra.Command, args []string) error {
+	afs = afero.NewOsFs()
+
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	offline, _ := cmd.Flags().GetBool("offline")
+
+	db, err := database.NewDatabase(cmd.Context(), afs)
+	if err != nil {
+		return err
+	}
+	defer func(db *database.Database) {
+		cobra.CheckErr(db.Close())
+	}(db)
+
+	dir := module.BinDir()
+	entries, err := afero.ReadDir(afs, dir)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	var results []adoptResult
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		m, err := module.NewModule(cmd.Context(), afs, "go")
+		if err != nil {
+			return err
+		}
+
+		r, err := adoptBinary(cmd.OutOrStderr(), db, m, filepath.Join(dir, entry.Name()), dryRun, offline)
+		if err != nil {
+			return err
+		}
+		results = append(results, r)
+	}
+
+	if len(results) == 0 {
+		cmd.Println("No binaries found in", dir)
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "BINARY\tMODULE\tVERSION\tSTATUS\tREASON")
+	for _, r := range results {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", r.Binary, r.Name, r.Version, r.Status, r.Reason)
+	}
+	return tw.Flush()
+}
+
+// adoptBinary records the binary at path unless it is not a Go binary built
+// from a module version, is already tracked or was renamed after install, in
+// which case updating it would install a second copy. Only database errors
+// are returned.
+func adoptBinary(out io.Writer, db *database.Database, m *module.Module, path string, dryRun, offline bool) (adoptResult, error) {
+	result := adoptResult{Binary: filepath.Base(path), Status: statusSkipped}
+
+	if err := m.LoadBinary(path); errors.Is(err, module.ErrNoVersion) {
+		result.Reason = "built from a local checkout"
+		return result, nil
+	} else if err != nil {
+		result.Reason = "not a Go binary"
+		return result, nil
+	}
+	result.Name, result.Version = m.Name, m.Version
+
+	if _, err := module.LoadModule(db, m.Name); err == nil {
+		result.Status = statusTracked
+		return result, nil
+	} else if !errors.Is(err, module.ErrNotTracked) {
+		return result, err
+	}
+
+	if expected := filepath.Base(m.BinaryPath()); expected != result.Binary {
+		result.Reason = fmt.Sprintf("go install names it %s", expected)
+		return result, nil
+	}
+
+	if dryRun {
+		result.Status, result.Reason = statusAdopted, "dry run"
+		return result, nil
+	}
+
+	if !offline {
+		_, _ = fmt.Fprintln(out, "Fetching versions:", m.Name)
+		if lr, err := m.FetchVersions(m.Name); err != nil {
+			_, _ = fmt.Fprintf(out, "Warning: %s: %v\n", m.Name, err)
+		} else {
+			m.Versions = lr.Versions
+		}
+	}
+
+	if err := m.Report(db); err != nil {
+		return result, err
+	}
+	if err := m.RecordEvent(db, m.Version, "adopt", path); err != nil {
+		return result, err
+	}
+
+	result.Status = statusAdopted
+	return result, nil
+}
@@ -0,0 +1,100 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/inovacc/goinstall/internal/database"
+	"github.com/inovacc/goinstall/internal/module"
+	"github.com/spf13/viper"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// defaultHealthTimeout bounds a health check without its own or a configured
+// default timeout.
+const defaultHealthTimeout = 10 * time.Second
+
+// healthCheck is one item of the health.checks config list: the arguments a
+// freshly installed binary of the module is run with and the exit code it has
+// to return within the timeout. It is a list for the reason given at
+// monitor.policyEntry.
+type healthCheck struct {
+	Module  string        `mapstructure:"module"`
+	Args    []string      `mapstructure:"args"`
+	Exit    int           `mapstructure:"exit"`
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// lookupHealthCheck returns the health check configured for the module called
+// name, or nil when it has none.
+func lookupHealthCheck(name string) (*healthCheck, error) {
+	var checks []healthCheck
+	if err := viper.UnmarshalKey("health.checks", &checks); err != nil {
+		return nil, fmt.Errorf("failed to read health.checks: %w", err)
+	}
+
+	for _, c := range checks {
+		if c.Module != name {
+			continue
+		}
+		if c.Timeout <= 0 {
+			c.Timeout = viper.GetDuration("health.timeout")
+		}
+		if c.Timeout <= 0 {
+			c.Timeout = defaultHealthTimeout
+		}
+		return &c, nil
+	}
+	return nil, nil
+}
+
+// run runs the binary at path and checks how it exits.
+func (c *healthCheck) run(ctx context.Context, path string) error {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, path, c.Args...)
+	// Children left running by a killed binary must not hold the check open.
+	cmd.WaitDelay = time.Second
+
+	out, err := cmd.CombinedOutput()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("no exit within %s", c.Timeout)
+	}
+
+	code := 0
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code = exitErr.ExitCode()
+	} else if err != nil {
+		return err
+	}
+
+	if code != c.Exit {
+		output := strings.TrimSpace(string(out))
+		if i := strings.LastIndexByte(output, '\n'); i >= 0 {
+			output = output[i+1:]
+		}
+		return fmt.Errorf("exit code %d, want %d: %s", code, c.Exit, output)
+	}
+	return nil
+}
+
+// checkHealth runs check against the freshly built binary of m at path, before
+// it is stored or linked, and records a failure as an install-failed event.
+func checkHealth(ctx context.Context, out io.Writer, db *database.Database, m *module.Module, check *healthCheck, path string) error {
+	_, _ = fmt.Fprintf(out, "Checking %s@%s: %s\n", m.Name, m.Version, strings.Join(append([]string{path}, check.Args...), " "))
+	failure := check.run(ctx, path)
+	if failure == nil {
+		return nil
+	}
+
+	failure = fmt.Errorf("health check of %s@%s failed: %w", m.Name, m.Version, failure)
+	if err := m.RecordEvent(db, m.Version, "install-failed", failure.Error()); err != nil {
+		return err
+	}
+	return failure
+}
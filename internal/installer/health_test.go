@@ -0,0 +1,209 @@
+package installer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/inovacc/goinstall/internal/database"
+	"github.com/inovacc/goinstall/internal/module"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+	modpath "golang.org/x/mod/module"
+	modzip "golang.org/x/mod/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLookupHealthCheck(t *testing.T) {
+	viper.Set("health.timeout", "3s")
+	viper.Set("health.checks", []map[string]any{
+		{"module": "mvdan.cc/gofumpt", "args": []string{"--version"}},
+		{"module": "golang.org/x/tools/cmd/stringer", "args": []string{"-h"}, "exit": 2, "timeout": "1s"},
+	})
+	defer func() {
+		viper.Set("health.timeout", nil)
+		viper.Set("health.checks", nil)
+	}()
+
+	c, err := lookupHealthCheck("mvdan.cc/gofumpt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c == nil || c.Exit != 0 || c.Timeout != 3*time.Second || len(c.Args) != 1 {
+		t.Fatalf("unexpected gofumpt check: %+v", c)
+	}
+
+	if c, err = lookupHealthCheck("golang.org/x/tools/cmd/stringer"); err != nil {
+		t.Fatal(err)
+	}
+	if c == nil || c.Exit != 2 || c.Timeout != time.Second {
+		t.Fatalf("unexpected stringer check: %+v", c)
+	}
+
+	if c, err = lookupHealthCheck("example.com/unchecked"); err != nil || c != nil {
+		t.Fatalf("expected no check, got %+v, %v", c, err)
+	}
+}
+
+func TestHealthCheck_run(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script")
+	}
+
+	script := filepath.Join(t.TempDir(), "tool")
+	body := "#!/bin/sh\ncase \"$1\" in\n--version) echo v1.0.0 ;;\n--hang) sleep 5 ;;\n*) echo \"unknown flag $1\" >&2; exit 2 ;;\nesac\n"
+	if err := os.WriteFile(script, []byte(body), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		check   healthCheck
+		wantErr string
+	}{
+		{healthCheck{Args: []string{"--version"}, Timeout: time.Second}, ""},
+		{healthCheck{Args: []string{"-x"}, Exit: 2, Timeout: time.Second}, ""},
+		{healthCheck{Args: []string{"-x"}, Timeout: time.Second}, "exit code 2, want 0: unknown flag -x"},
+		{healthCheck{Args: []string{"--hang"}, Timeout: 100 * time.Millisecond}, "no exit within 100ms"},
+	}
+
+	for _, tt := range tests {
+		err := tt.check.run(context.Background(), script)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("run(%v): %v", tt.check.Args, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("run(%v): expected error %q, got %v", tt.check.Args, tt.wantErr, err)
+		}
+	}
+}
+
+// writeProxyModule publishes example.com/tool at version, a command exiting
+// with code, in the file proxy at dir.
+func writeProxyModule(t *testing.T, dir, version string, code int) {
+	t.Helper()
+
+	src := t.TempDir()
+	files := map[string]string{
+		"go.mod":  "module example.com/tool\n\ngo 1.21\n",
+		"main.go": fmt.Sprintf("package main\n\nimport \"os\"\n\nfunc main() { os.Exit(%d) }\n", code),
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(src, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	v := filepath.Join(dir, "example.com", "tool", "@v")
+	if err := os.MkdirAll(v, 0755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(filepath.Join(v, version+".zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := modzip.CreateFromDir(f, modpath.Version{Path: "example.com/tool", Version: version}, src); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := os.OpenFile(filepath.Join(v, "list"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = fmt.Fprintln(list, version)
+	if err := list.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string]string{
+		version + ".mod":  files["go.mod"],
+		version + ".info": `{"Version":"` + version + `","Time":"2024-01-01T00:00:00Z"}`,
+	} {
+		if err := os.WriteFile(filepath.Join(v, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestInstall_HealthCheck(t *testing.T) {
+	proxy, dir := t.TempDir(), t.TempDir()
+	writeProxyModule(t, proxy, "v1.0.0", 0)
+	writeProxyModule(t, proxy, "v1.1.0", 1)
+
+	t.Setenv("GOPROXY", "file://"+filepath.ToSlash(proxy))
+	t.Setenv("GONOPROXY", "")
+	t.Setenv("GOPRIVATE", "")
+	t.Setenv("GOSUMDB", "off")
+	t.Setenv("GOFLAGS", "-modcacherw")
+	t.Setenv("GOMODCACHE", filepath.Join(dir, "modcache"))
+	t.Setenv("GOBIN", filepath.Join(dir, "bin"))
+	viper.Set("installPath", filepath.Join(dir, "modules.db"))
+	viper.Set("health.checks", []map[string]any{{"module": "example.com/tool"}})
+	defer viper.Set("health.checks", nil)
+
+	afs = afero.NewOsFs()
+	db, err := database.NewDatabase(context.TODO(), afs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(db *database.Database) {
+		_ = db.Close()
+	}(db)
+
+	install := func(version string) (*module.Module, error) {
+		m, err := module.NewModule(context.TODO(), afs, "go")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m, Install(context.TODO(), io.Discard, db, m, "example.com/tool@"+version)
+	}
+
+	m, err := install("v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The working version predates the store: the GOBIN entry is a plain
+	// binary and the store holds nothing to go back to.
+	working, err := os.ReadFile(m.BinaryPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(m.BinaryPath()); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(m.BinaryPath(), working, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(module.StoreDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := install("v1.1.0"); err == nil || !strings.Contains(err.Error(), "exit code 1, want 0") {
+		t.Fatalf("expected the health check to fail, got %v", err)
+	}
+
+	if data, err := os.ReadFile(m.BinaryPath()); err != nil || !bytes.Equal(data, working) {
+		t.Fatalf("expected the GOBIN entry to keep running v1.0.0, got %v", err)
+	}
+	if installed, err := module.LoadModule(db, "example.com/tool"); err != nil || installed.Version != "v1.0.0" {
+		t.Fatalf("expected v1.0.0 to stay recorded, got %+v, %v", installed, err)
+	}
+	_ = filepath.Walk(module.StoreDir(), func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			t.Errorf("expected the failed build to stay out of the store, found %s", path)
+		}
+		return nil
+	})
+
+	var events int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM events WHERE module_name = ? AND version = ? AND action = ?`, "example.com/tool", "v1.1.0", "install-failed").Scan(&events); err != nil || events != 1 {
+		t.Fatalf("expected one install-failed event, got %d, %v", events, err)
+	}
+}
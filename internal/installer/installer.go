@@ -1,10 +1,15 @@
 package installer
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/inovacc/goinstall/internal/database"
 	"github.com/inovacc/goinstall/internal/module"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"io"
 )
 
 var afs afero.Fs
@@ -27,19 +32,108 @@ func Installer(cmd *cobra.Command, args []string) error {
 
 	name := args[0]
 
-	cmd.Println("Fetching module information...")
-	if err := newModule.FetchModuleInfo(name); err == nil {
-		cmd.Println("Installing module:", newModule.Name)
-		if err := newModule.InstallModule(cmd.Context()); err != nil {
+	switch {
+	case viper.GetBool("remove"):
+		return removeModule(cmd, db, newModule, name)
+	case viper.GetBool("update"):
+		current, err := loadTracked(db, "update", name)
+		if err != nil {
 			return err
 		}
+		return checkUpdate(cmd.Context(), cmd.OutOrStderr(), db, newModule, current).Err
+	}
 
-		if err := newModule.Report(db); err != nil {
-			return err
-		}
+	if err := Install(cmd.Context(), cmd.OutOrStderr(), db, newModule, name); err != nil {
+		return err
 	}
 
 	cmd.Println("Module is installer successfully:", newModule.Name)
 	cmd.Printf("Show report using: %s report %s\n", cmd.Root().Name(), newModule.Name)
 	return nil
 }
+
+// Install fetches the module described by name, which may carry a version
+// suffix, installs it and records it in the database. A module with a
+// configured health check is only recorded once its new binary passes it.
+func Install(ctx context.Context, out io.Writer, db *database.Database, m *module.Module, name string) error {
+	if err := fetch(out, m, name); err != nil {
+		return err
+	}
+	return install(ctx, out, db, m)
+}
+
+// fetch resolves the module described by name and its dependencies into m.
+func fetch(out io.Writer, m *module.Module, name string) error {
+	_, _ = fmt.Fprintln(out, "Fetching module information...")
+	m.SetConcurrency(viper.GetInt("dependencies.jobs"))
+	if err := m.FetchModuleInfo(name); err != nil {
+		return err
+	}
+
+	for _, d := range m.Dependencies {
+		if d.Error != "" {
+			_, _ = fmt.Fprintf(out, "Warning: dependency %s@%s: %s\n", d.Name, d.Version, d.Error)
+		}
+	}
+	return nil
+}
+
+// install installs the module fetched into m and records it.
+func install(ctx context.Context, out io.Writer, db *database.Database, m *module.Module) error {
+	if err := checkLicenses(out, m); err != nil {
+		return err
+	}
+
+	shimmed, err := module.ShimEnabled(db, m.Name)
+	if err != nil {
+		return err
+	}
+	m.Shim = shimmed
+
+	check, err := lookupHealthCheck(m.Name)
+	if err != nil {
+		return err
+	}
+	var verify func(string) error
+	if check != nil {
+		verify = func(path string) error {
+			return checkHealth(ctx, out, db, m, check, path)
+		}
+	}
+
+	_, _ = fmt.Fprintln(out, "Installing module:", m.Name)
+	if err := m.InstallModule(ctx, verify); err != nil {
+		return err
+	}
+	return m.Report(db)
+}
+
+func removeModule(cmd *cobra.Command, db *database.Database, m *module.Module, name string) error {
+	current, err := loadTracked(db, "remove", name)
+	if err != nil {
+		return err
+	}
+	if err := m.Load(db, current.Name); err != nil {
+		return err
+	}
+
+	cmd.Println("Removing binary:", m.BinaryPath())
+	if err := m.UninstallModule(); err != nil {
+		return err
+	}
+
+	if err := m.Purge(db); err != nil {
+		return err
+	}
+
+	cmd.Println("Module is removed successfully:", m.Name)
+	return nil
+}
+
+func loadTracked(db *database.Database, action, name string) (*module.Module, error) {
+	current, err := module.LoadModule(db, module.ParseName(name))
+	if errors.Is(err, module.ErrNotTracked) {
+		return nil, fmt.Errorf("cannot %s %s: %w", action, module.ParseName(name), err)
+	}
+	return current, err
+}
@@ -0,0 +1,96 @@
+package installer
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/inovacc/goinstall/internal/database"
+	"github.com/inovacc/goinstall/internal/inventory"
+	"github.com/inovacc/goinstall/internal/manifest"
+	"github.com/inovacc/goinstall/internal/module"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+// Export writes the inventory of every installed module to the given file,
+// or to stdout.
+func Export(cmd *cobra.Command, args []string) error {
+	afs = afero.NewOsFs()
+
+	db, err := database.NewDatabase(cmd.Context(), afs)
+	if err != nil {
+		return err
+	}
+	defer func(db *database.Database) {
+		cobra.CheckErr(db.Close())
+	}(db)
+
+	inv, err := inventory.FromDatabase(db)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return inv.Write(cmd.OutOrStdout())
+	}
+
+	var buf bytes.Buffer
+	if err := inv.Write(&buf); err != nil {
+		return err
+	}
+	if err := afero.WriteFile(afs, args[0], buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write inventory: %w", err)
+	}
+
+	cmd.Printf("Exported %d modules to %s\n", len(inv.Modules), args[0])
+	return nil
+}
+
+// Import installs the modules of an inventory at their exported versions,
+// with their build tags, aliases and version queries. Modules already
+// installed the same way are left alone.
+func Import(cmd *cobra.Command, args []string) error {
+	afs = afero.NewOsFs()
+
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	inv, err := inventory.Load(afs, args[0])
+	if err != nil {
+		return err
+	}
+
+	db, err := database.NewDatabase(cmd.Context(), afs)
+	if err != nil {
+		return err
+	}
+	defer func(db *database.Database) {
+		cobra.CheckErr(db.Close())
+	}(db)
+
+	installed, err := module.LoadInstalled(db)
+	if err != nil {
+		return err
+	}
+
+	mf := &manifest.Manifest{Path: args[0], Tools: make([]manifest.Tool, 0, len(inv.Modules))}
+	queries := make(map[string]string, len(inv.Modules))
+	for _, m := range inv.Modules {
+		mf.Tools = append(mf.Tools, manifest.Tool{Module: m.Name, Version: m.Version, Tags: m.Tags, Alias: m.Alias})
+		queries[m.Name] = m.Query
+	}
+
+	steps := planSync(mf, installed, false)
+	for i := range steps {
+		if q := queries[steps[i].Name]; q != "" {
+			steps[i].query = q
+		}
+	}
+	if len(steps) == 0 {
+		cmd.Printf("Installed modules match %s\n", args[0])
+		return nil
+	}
+
+	if dryRun {
+		return printSteps(cmd.OutOrStdout(), steps, nil)
+	}
+	return runSteps(cmd, db, steps)
+}
@@ -0,0 +1,57 @@
+package installer
+
+import (
+	"fmt"
+	"github.com/inovacc/goinstall/internal/license"
+	"github.com/inovacc/goinstall/internal/module"
+	"github.com/spf13/viper"
+	"io"
+	"strings"
+)
+
+// checkLicenses enforces the license.deny setting on the tool module and the
+// dependencies that are built into it. With license.allow the denied
+// licenses are reported and the install goes ahead.
+func checkLicenses(out io.Writer, m *module.Module) error {
+	deny := viper.GetStringSlice("license.deny")
+	if len(deny) == 0 {
+		return nil
+	}
+
+	violations := deniedModules(m, deny)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	if viper.GetBool("license.allow") {
+		for _, v := range violations {
+			_, _ = fmt.Fprintf(out, "Warning: %s has a denied license\n", v)
+		}
+		return nil
+	}
+	return fmt.Errorf("%s would pull in denied licenses: %s (use --allow-denied-licenses to install anyway)", m.Name, strings.Join(violations, ", "))
+}
+
+// deniedModules lists the modules of m, the tool first, whose license
+// matches deny, as module@version (licenses).
+func deniedModules(m *module.Module, deny []string) []string {
+	var violations []string
+	add := func(name, version, expr string) {
+		if expr == "" {
+			return
+		}
+		if denied := license.Denied(expr, deny); len(denied) > 0 {
+			violations = append(violations, fmt.Sprintf("%s@%s (%s)", name, version, strings.Join(denied, ", ")))
+		}
+	}
+
+	// The module of a tool below its root is also one of its dependencies.
+	root := m.ModuleRoot()
+	add(root, m.Version, m.License)
+	for _, d := range m.Dependencies {
+		if d.Name != root {
+			add(d.Name, d.Version, d.License)
+		}
+	}
+	return violations
+}
@@ -0,0 +1,33 @@
+package installer
+
+import (
+	"github.com/inovacc/goinstall/internal/module"
+	"slices"
+	"testing"
+)
+
+func TestDeniedModules(t *testing.T) {
+	m := &module.Module{
+		Name:    "example.com/tool/cmd/tool",
+		Version: "v1.2.0",
+		License: "AGPL-3.0",
+		Dependencies: []module.Dependency{
+			{Name: "example.com/tool", Version: "v1.2.0", License: "AGPL-3.0"},
+			{Name: "golang.org/x/mod", Version: "v0.27.0", License: "BSD-3-Clause"},
+			{Name: "example.com/gpl", Version: "v0.3.0", License: "GPL-2.0 AND MIT"},
+			{Name: "example.com/modonly", Version: "v0.1.0"},
+		},
+	}
+
+	want := []string{
+		"example.com/tool@v1.2.0 (AGPL-3.0)",
+		"example.com/gpl@v0.3.0 (GPL-2.0)",
+	}
+	if got := deniedModules(m, []string{"AGPL", "GPL"}); !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	if got := deniedModules(m, []string{"LGPL"}); got != nil {
+		t.Errorf("expected no violations, got %v", got)
+	}
+}
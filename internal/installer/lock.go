@@ -0,0 +1,136 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/inovacc/goinstall/internal/database"
+	"github.com/inovacc/goinstall/internal/manifest"
+	"github.com/inovacc/goinstall/internal/module"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"io"
+	"strings"
+)
+
+// Lock resolves every tool of the manifest to an exact version and writes it
+// to the lockfile next to the manifest, with the h1: hash of its module and
+// the versions of its dependencies.
+func Lock(cmd *cobra.Command, args []string) error {
+	afs = afero.NewOsFs()
+
+	file, _ := cmd.Flags().GetString("file")
+	mf, err := manifest.Load(file)
+	if err != nil {
+		return err
+	}
+
+	db, err := database.NewDatabase(cmd.Context(), afs)
+	if err != nil {
+		return err
+	}
+	defer func(db *database.Database) {
+		cobra.CheckErr(db.Close())
+	}(db)
+
+	lock := &manifest.Lock{Tools: make([]manifest.LockedTool, 0, len(mf.Tools))}
+	for i := range mf.Tools {
+		lt, err := lockTool(cmd.Context(), cmd.OutOrStderr(), db, &mf.Tools[i])
+		if err != nil {
+			return fmt.Errorf("failed to lock %s: %w", mf.Tools[i].Module, err)
+		}
+		lock.Tools = append(lock.Tools, lt)
+	}
+
+	path := manifest.LockPath(mf.Path)
+	if err := lock.Save(path); err != nil {
+		return err
+	}
+
+	cmd.Printf("Locked %d tools in %s\n", len(lock.Tools), path)
+	return nil
+}
+
+// lockTool pins t to the installed version when it meets the manifest, so
+// the lock describes what is installed, and resolves its query otherwise.
+func lockTool(ctx context.Context, out io.Writer, db *database.Database, t *manifest.Tool) (manifest.LockedTool, error) {
+	m, err := module.NewModule(ctx, afs, "go")
+	if err != nil {
+		return manifest.LockedTool{}, err
+	}
+
+	current, err := module.LoadModule(db, t.Module)
+	switch {
+	case err == nil && (t.Version == "" || module.Satisfies(t.Version, current.Version)):
+		if err := m.Load(db, t.Module); err != nil {
+			return manifest.LockedTool{}, err
+		}
+	case err == nil || errors.Is(err, module.ErrNotTracked):
+		query := t.Version
+		if query == "" {
+			query = "latest"
+		}
+		_, _ = fmt.Fprintf(out, "Resolving %s@%s\n", t.Module, query)
+		if err := m.FetchModuleInfo(fmt.Sprintf("%s@%s", t.Module, query)); err != nil {
+			return manifest.LockedTool{}, err
+		}
+	default:
+		return manifest.LockedTool{}, err
+	}
+
+	root := m.ModuleRoot()
+	sum, err := m.DownloadSum(root, m.Version)
+	if err != nil {
+		return manifest.LockedTool{}, err
+	}
+
+	lt := manifest.LockedTool{
+		Module:  t.Module,
+		Query:   t.Version,
+		Root:    root,
+		Version: m.Version,
+		Sum:     sum,
+		Tags:    t.Tags,
+		Alias:   t.Alias,
+	}
+	for _, d := range m.Dependencies {
+		lt.Dependencies = append(lt.Dependencies, manifest.LockedDependency{Module: d.Name, Version: d.Version})
+	}
+	return lt, nil
+}
+
+// verifyLocked checks that the module of a locked tool still hashes to the
+// locked sum upstream before it is installed.
+func verifyLocked(m *module.Module, lt *manifest.LockedTool) error {
+	sum, err := m.DownloadSum(lt.Root, lt.Version)
+	if err != nil {
+		return err
+	}
+	if sum != lt.Sum {
+		return fmt.Errorf("checksum mismatch for %s@%s: locked %s, upstream %s", lt.Root, lt.Version, lt.Sum, sum)
+	}
+	return nil
+}
+
+// verifyLockedDependencies checks that the module fetched into m selects every
+// dependency of its locked tool at the locked version.
+func verifyLockedDependencies(m *module.Module, lt *manifest.LockedTool) error {
+	versions := make(map[string]string, len(m.Dependencies))
+	for _, d := range m.Dependencies {
+		versions[d.Name] = d.Version
+	}
+
+	var drifted []string
+	for _, d := range lt.Dependencies {
+		switch v, ok := versions[d.Module]; {
+		case !ok:
+			drifted = append(drifted, fmt.Sprintf("%s@%s (no longer required)", d.Module, d.Version))
+		case v != d.Version:
+			drifted = append(drifted, fmt.Sprintf("%s@%s (now %s)", d.Module, d.Version, v))
+		}
+	}
+	if len(drifted) > 0 {
+		return fmt.Errorf("dependencies of %s@%s differ from the lock: %s", lt.Module, lt.Version, strings.Join(drifted, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package installer
+
+import (
+	"github.com/inovacc/goinstall/internal/manifest"
+	"github.com/inovacc/goinstall/internal/module"
+	"strings"
+	"testing"
+)
+
+func TestVerifyLockedDependencies(t *testing.T) {
+	lt := &manifest.LockedTool{
+		Module:  "mvdan.cc/gofumpt",
+		Version: "v0.9.2",
+		Dependencies: []manifest.LockedDependency{
+			{Module: "golang.org/x/mod", Version: "v0.27.0"},
+			{Module: "golang.org/x/sync", Version: "v0.16.0"},
+		},
+	}
+
+	m := &module.Module{Dependencies: []module.Dependency{
+		{Name: "golang.org/x/mod", Version: "v0.27.0"},
+		{Name: "golang.org/x/sync", Version: "v0.16.0"},
+		{Name: "golang.org/x/tools", Version: "v0.36.0"},
+	}}
+	if err := verifyLockedDependencies(m, lt); err != nil {
+		t.Fatal(err)
+	}
+
+	m.Dependencies = []module.Dependency{{Name: "golang.org/x/mod", Version: "v0.28.0"}}
+	err := verifyLockedDependencies(m, lt)
+	if err == nil {
+		t.Fatal("expected drifted dependencies to fail")
+	}
+	for _, want := range []string{"golang.org/x/mod@v0.27.0 (now v0.28.0)", "golang.org/x/sync@v0.16.0 (no longer required)"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"github.com/inovacc/goinstall/internal/database"
+	"github.com/inovacc/goinstall/internal/module"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+// Rollback switches the module back to the version installed before the
+// current one, or to the version given with --to, by pointing its GOBIN entry
+// at the binary kept in the store. Nothing is fetched or built.
+func Rollback(cmd *cobra.Command, args []string) error {
+	afs = afero.NewOsFs()
+
+	to, _ := cmd.Flags().GetString("to")
+
+	db, err := database.NewDatabase(cmd.Context(), afs)
+	if err != nil {
+		return err
+	}
+	defer func(db *database.Database) {
+		cobra.CheckErr(db.Close())
+	}(db)
+
+	current, err := loadTracked(db, "roll back", args[0])
+	if err != nil {
+		return err
+	}
+
+	history, err := module.LoadHistory(db, current.Name)
+	if err != nil {
+		return err
+	}
+
+	target, err := rollbackTarget(history, to, func(m *module.Module) bool { return m.Stored(afs) })
+	if err != nil {
+		return err
+	}
+
+	return activate(cmd, db, current, target.Version, "rollback")
+}
+
+// activate makes the stored version of the current module the installed one
+// and records the switch as an event of the given action.
+func activate(cmd *cobra.Command, db *database.Database, current *module.Module, version, action string) error {
+	m, err := module.NewModule(cmd.Context(), afs, "go")
+	if err != nil {
+		return err
+	}
+	if err := m.LoadVersion(db, current.Name, version); err != nil {
+		return err
+	}
+	if m.Shim, err = module.ShimEnabled(db, m.Name); err != nil {
+		return err
+	}
+	if err := m.Activate(db); err != nil {
+		return err
+	}
+
+	// The version switched to may have been installed under another alias.
+	if old := current.BinaryPath(); old != m.BinaryPath() {
+		if err := afs.Remove(old); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove previous binary: %w", err)
+		}
+	}
+
+	if err := m.RecordEvent(db, m.Version, action, fmt.Sprintf("%s -> %s", current.Version, m.Version)); err != nil {
+		return err
+	}
+
+	cmd.Printf("Switched %s from %s to %s\n", m.Name, current.Version, m.Version)
+	return nil
+}
+
+// rollbackTarget picks the version to switch to from the install history,
+// oldest first with the current version last: the given version, or the most
+// recently installed one before the current version whose binary is stored.
+func rollbackTarget(history []module.Module, version string, stored func(*module.Module) bool) (*module.Module, error) {
+	current := &history[len(history)-1]
+
+	if version != "" {
+		if version == current.Version {
+			return nil, fmt.Errorf("%s is already at %s", current.Name, version)
+		}
+		for i := range history {
+			if history[i].Version != version {
+				continue
+			}
+			if !stored(&history[i]) {
+				return nil, fmt.Errorf("%s@%s is not in the store", current.Name, version)
+			}
+			return &history[i], nil
+		}
+		return nil, fmt.Errorf("%s@%s was never installed", current.Name, version)
+	}
+
+	for i := len(history) - 2; i >= 0; i-- {
+		if history[i].Version != current.Version && stored(&history[i]) {
+			return &history[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no previous version of %s in the store", current.Name)
+}
@@ -0,0 +1,50 @@
+package installer
+
+import (
+	"github.com/inovacc/goinstall/internal/module"
+	"testing"
+)
+
+func TestRollbackTarget(t *testing.T) {
+	history := []module.Module{
+		{Name: "mvdan.cc/gofumpt", Version: "v0.7.0"},
+		{Name: "mvdan.cc/gofumpt", Version: "v0.8.0"},
+		{Name: "mvdan.cc/gofumpt", Version: "v0.9.0"},
+		{Name: "mvdan.cc/gofumpt", Version: "v0.9.2"},
+	}
+	// v0.9.0 was installed before the store existed.
+	stored := func(m *module.Module) bool { return m.Version != "v0.9.0" }
+
+	tests := []struct {
+		version string
+		want    string
+		wantErr bool
+	}{
+		{version: "", want: "v0.8.0"},
+		{version: "v0.7.0", want: "v0.7.0"},
+		{version: "v0.9.0", wantErr: true},
+		{version: "v0.9.2", wantErr: true},
+		{version: "v0.1.0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := rollbackTarget(history, tt.version, stored)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("rollbackTarget(%q): expected an error, got %s", tt.version, got.Version)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("rollbackTarget(%q): %v", tt.version, err)
+			continue
+		}
+		if got.Version != tt.want {
+			t.Errorf("rollbackTarget(%q) = %s, want %s", tt.version, got.Version, tt.want)
+		}
+	}
+
+	if _, err := rollbackTarget(history[:1], "", stored); err == nil {
+		t.Error("expected an error without a previous version")
+	}
+}
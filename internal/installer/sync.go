@@ -0,0 +1,293 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/inovacc/goinstall/internal/database"
+	"github.com/inovacc/goinstall/internal/manifest"
+	"github.com/inovacc/goinstall/internal/module"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	actionInstall = "install"
+	actionChange  = "change"
+	actionRemove  = "remove"
+)
+
+// syncStep is one change needed to bring the installed modules in line with
+// the manifest.
+type syncStep struct {
+	Action  string
+	Name    string
+	From    string
+	To      string
+	Reason  string
+	tool    *manifest.Tool
+	current *module.Module
+	locked  *manifest.LockedTool
+	// query replaces the version query recorded by the install, so updates
+	// keep following the constraint an imported module was installed with.
+	query string
+}
+
+// Sync installs the tools of the manifest that are missing, reinstalls the
+// ones whose version, build tags or alias differ and, with --prune, removes
+// the installed modules the manifest does not list. With --frozen the tools
+// are installed at the versions of the lockfile, after checking their module
+// hashes upstream.
+func Sync(cmd *cobra.Command, args []string) error {
+	afs = afero.NewOsFs()
+
+	file, _ := cmd.Flags().GetString("file")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	prune, _ := cmd.Flags().GetBool("prune")
+	frozen, _ := cmd.Flags().GetBool("frozen")
+
+	mf, err := manifest.Load(file)
+	if err != nil {
+		return err
+	}
+
+	var locked map[string]manifest.LockedTool
+	if frozen {
+		lock, err := manifest.LoadLock(manifest.LockPath(mf.Path))
+		if err != nil {
+			return err
+		}
+		if mf, locked, err = lock.Pin(mf); err != nil {
+			return err
+		}
+	}
+
+	db, err := database.NewDatabase(cmd.Context(), afs)
+	if err != nil {
+		return err
+	}
+	defer func(db *database.Database) {
+		cobra.CheckErr(db.Close())
+	}(db)
+
+	installed, err := module.LoadInstalled(db)
+	if err != nil {
+		return err
+	}
+
+	steps := planSync(mf, installed, prune)
+	for i := range steps {
+		if lt, ok := locked[steps[i].Name]; ok {
+			steps[i].locked = &lt
+		}
+	}
+	if len(steps) == 0 {
+		cmd.Printf("Installed modules match %s\n", mf.Path)
+		return nil
+	}
+
+	if dryRun {
+		return printSteps(cmd.OutOrStdout(), steps, nil)
+	}
+	return runSteps(cmd, db, steps)
+}
+
+// runSteps runs every step, whether or not the previous ones failed, and
+// prints their outcome.
+func runSteps(cmd *cobra.Command, db *database.Database, steps []syncStep) error {
+	errs := make([]error, len(steps))
+	failed := 0
+	for i := range steps {
+		if errs[i] = runStep(cmd, db, &steps[i]); errs[i] != nil {
+			failed++
+		}
+	}
+
+	if err := printSteps(cmd.OutOrStdout(), steps, errs); err != nil {
+		return err
+	}
+	if failed > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d of %d sync steps failed", failed, len(steps))
+	}
+	return nil
+}
+
+// planSync compares the manifest with the installed modules. A tool without
+// a version accepts whatever version is installed; otherwise the installed
+// version has to meet its query, see versionMet.
+func planSync(mf *manifest.Manifest, installed []module.Module, prune bool) []syncStep {
+	current := make(map[string]*module.Module, len(installed))
+	for i := range installed {
+		current[installed[i].Name] = &installed[i]
+	}
+
+	var steps []syncStep
+	for i := range mf.Tools {
+		t := &mf.Tools[i]
+		want := t.Version
+		if want == "" {
+			want = "latest"
+		}
+
+		m, ok := current[t.Module]
+		if !ok {
+			steps = append(steps, syncStep{Action: actionInstall, Name: t.Module, To: want, tool: t})
+			continue
+		}
+
+		var reasons []string
+		if !versionMet(t.Version, m) {
+			reasons = append(reasons, "version")
+		}
+		if !slices.Equal(module.SortedTags(t.Tags), module.SortedTags(m.Tags)) {
+			reasons = append(reasons, "tags")
+		}
+		if t.Alias != m.Alias {
+			reasons = append(reasons, "alias")
+		}
+		if len(reasons) == 0 {
+			continue
+		}
+
+		to, query := want, ""
+		if !slices.Contains(reasons, "version") {
+			// Reinstalling at the installed version must not replace the
+			// query it follows, or updates and later syncs would lose it.
+			to, query = m.Version, t.Version
+			if query == "" {
+				query = m.Query
+			}
+		}
+		steps = append(steps, syncStep{
+			Action:  actionChange,
+			Name:    t.Module,
+			From:    m.Version,
+			To:      to,
+			Reason:  strings.Join(reasons, ", "),
+			tool:    t,
+			current: m,
+			query:   query,
+		})
+	}
+
+	if prune {
+		listed := make(map[string]struct{}, len(mf.Tools))
+		for _, t := range mf.Tools {
+			listed[t.Module] = struct{}{}
+		}
+		for i := range installed {
+			if _, ok := listed[installed[i].Name]; !ok {
+				steps = append(steps, syncStep{Action: actionRemove, Name: installed[i].Name, From: installed[i].Version, Reason: "not in manifest"})
+			}
+		}
+	}
+	return steps
+}
+
+// versionMet reports whether the installed module m meets the version query
+// of a manifest tool. Like no version, latest accepts whatever is installed,
+// since moving it is what updates are for. A query that depends on what is
+// published, such as a branch, is met by a module installed with it.
+func versionMet(query string, m *module.Module) bool {
+	switch query {
+	case "", "latest":
+		return true
+	case m.Query:
+		return true
+	default:
+		return module.Satisfies(query, m.Version)
+	}
+}
+
+func runStep(cmd *cobra.Command, db *database.Database, s *syncStep) error {
+	m, err := module.NewModule(cmd.Context(), afs, "go")
+	if err != nil {
+		return err
+	}
+
+	if s.Action == actionRemove {
+		return removeModule(cmd, db, m, s.Name)
+	}
+
+	if s.current != nil {
+		// Upgrade and patch queries are relative to the installed version.
+		if err := m.Load(db, s.Name); err != nil {
+			return err
+		}
+	}
+	m.Tags = s.tool.Tags
+	m.Alias = s.tool.Alias
+
+	if s.locked != nil {
+		if err := verifyLocked(m, s.locked); err != nil {
+			return err
+		}
+	}
+
+	if err := syncInstall(cmd.Context(), cmd.OutOrStderr(), db, m, s); err != nil {
+		return err
+	}
+	s.To = m.Version
+	return nil
+}
+
+// syncInstall installs the tool of s at its query, or at the installed
+// version when only its tags or alias changed. A locked tool is only
+// installed when it builds with the locked dependency versions. The binary
+// of the previous alias is removed once the new one is in place.
+func syncInstall(ctx context.Context, out io.Writer, db *database.Database, m *module.Module, s *syncStep) error {
+	if err := fetch(out, m, fmt.Sprintf("%s@%s", s.Name, s.To)); err != nil {
+		return err
+	}
+	if s.locked != nil {
+		if err := verifyLockedDependencies(m, s.locked); err != nil {
+			return err
+		}
+	}
+	if err := install(ctx, out, db, m); err != nil {
+		return err
+	}
+
+	if s.query != "" && s.query != m.Query {
+		m.Query = s.query
+		if err := m.Report(db); err != nil {
+			return err
+		}
+	}
+
+	if s.current != nil {
+		if old := s.current.BinaryPath(); old != m.BinaryPath() {
+			if err := afs.Remove(old); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("failed to remove previous binary: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+func printSteps(w io.Writer, steps []syncStep, errs []error) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if errs == nil {
+		_, _ = fmt.Fprintln(tw, "ACTION\tMODULE\tFROM\tTO\tREASON")
+		for _, s := range steps {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", s.Action, s.Name, s.From, s.To, s.Reason)
+		}
+		return tw.Flush()
+	}
+
+	_, _ = fmt.Fprintln(tw, "ACTION\tMODULE\tFROM\tTO\tSTATUS\tREASON")
+	for i, s := range steps {
+		status, reason := "done", s.Reason
+		if errs[i] != nil {
+			status, reason = statusFailed, errs[i].Error()
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", s.Action, s.Name, s.From, s.To, status, reason)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,59 @@
+package installer
+
+import (
+	"github.com/inovacc/goinstall/internal/manifest"
+	"github.com/inovacc/goinstall/internal/module"
+	"testing"
+)
+
+func TestPlanSync(t *testing.T) {
+	mf := &manifest.Manifest{Tools: []manifest.Tool{
+		{Module: "mvdan.cc/gofumpt", Version: "^0.9"},
+		{Module: "golang.org/x/tools/cmd/stringer", Tags: []string{"netgo"}},
+		{Module: "github.com/inovacc/ksuid/cmd/ksuid", Version: "v0.2.0"},
+		{Module: "golang.org/x/vuln/cmd/govulncheck"},
+		{Module: "github.com/golangci/golangci-lint/cmd/golangci-lint", Version: "latest"},
+		{Module: "golang.org/x/tools/gopls", Version: "master"},
+		{Module: "github.com/go-delve/delve/cmd/dlv", Version: "master"},
+		{Module: "honnef.co/go/tools/cmd/staticcheck", Version: "^0.6", Tags: []string{"netgo"}},
+		{Module: "github.com/goreleaser/goreleaser/v2", Version: "main", Tags: []string{"netgo"}},
+	}}
+	installed := []module.Module{
+		{Name: "mvdan.cc/gofumpt", Version: "v0.9.2"},
+		{Name: "golang.org/x/tools/cmd/stringer", Version: "v0.36.0"},
+		{Name: "github.com/inovacc/ksuid/cmd/ksuid", Version: "v0.1.0", Alias: "ksuid-dev"},
+		{Name: "github.com/rakyll/hey", Version: "v0.1.4"},
+		{Name: "github.com/golangci/golangci-lint/cmd/golangci-lint", Version: "v1.64.8", Query: "v1.64.8"},
+		{Name: "golang.org/x/tools/gopls", Version: "v0.20.1-0.20250801000000-abcdefabcdef", Query: "master"},
+		{Name: "github.com/go-delve/delve/cmd/dlv", Version: "v1.25.1", Query: "latest"},
+		{Name: "honnef.co/go/tools/cmd/staticcheck", Version: "v0.6.1", Query: "^0.6"},
+		{Name: "github.com/goreleaser/goreleaser/v2", Version: "v2.12.1-0.20250901000000-abcdefabcdef", Query: "main"},
+	}
+
+	want := []syncStep{
+		{Action: actionChange, Name: "golang.org/x/tools/cmd/stringer", From: "v0.36.0", To: "v0.36.0", Reason: "tags"},
+		{Action: actionChange, Name: "github.com/inovacc/ksuid/cmd/ksuid", From: "v0.1.0", To: "v0.2.0", Reason: "version, alias"},
+		{Action: actionInstall, Name: "golang.org/x/vuln/cmd/govulncheck", To: "latest"},
+		{Action: actionChange, Name: "github.com/go-delve/delve/cmd/dlv", From: "v1.25.1", To: "master", Reason: "version"},
+		{Action: actionChange, Name: "honnef.co/go/tools/cmd/staticcheck", From: "v0.6.1", To: "v0.6.1", Reason: "tags", query: "^0.6"},
+		{Action: actionChange, Name: "github.com/goreleaser/goreleaser/v2", From: "v2.12.1-0.20250901000000-abcdefabcdef", To: "v2.12.1-0.20250901000000-abcdefabcdef", Reason: "tags", query: "main"},
+	}
+	checkSteps(t, planSync(mf, installed, false), want)
+
+	want = append(want, syncStep{Action: actionRemove, Name: "github.com/rakyll/hey", From: "v0.1.4", Reason: "not in manifest"})
+	checkSteps(t, planSync(mf, installed, true), want)
+}
+
+func checkSteps(t *testing.T, got, want []syncStep) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("expected %d steps but got %+v", len(want), got)
+	}
+	for i := range want {
+		g := got[i]
+		g.tool, g.current = nil, nil
+		if g != want[i] {
+			t.Fatalf("step %d: expected %+v but got %+v", i, want[i], g)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"github.com/inovacc/goinstall/internal/database"
+	"github.com/inovacc/goinstall/internal/module"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/mod/semver"
+	"io"
+	"sync"
+	"text/tabwriter"
+)
+
+const (
+	statusUpgraded = "upgraded"
+	statusCurrent  = "current"
+	statusFailed   = "failed"
+)
+
+type updateResult struct {
+	Name   string
+	From   string
+	To     string
+	Status string
+	Err    error
+}
+
+// Update checks the given modules, or every tracked module with --all, and
+// upgrades the outdated ones concurrently.
+func Update(cmd *cobra.Command, args []string) error {
+	afs = afero.NewOsFs()
+
+	all, _ := cmd.Flags().GetBool("all")
+	if all == (len(args) > 0) {
+		return fmt.Errorf("either --all or at least one module is required")
+	}
+
+	db, err := database.NewDatabase(cmd.Context(), afs)
+	if err != nil {
+		return err
+	}
+	defer func(db *database.Database) {
+		cobra.CheckErr(db.Close())
+	}(db)
+
+	var targets []module.Module
+	if all {
+		if targets, err = module.LoadInstalled(db); err != nil {
+			return err
+		}
+	} else {
+		for _, name := range args {
+			current, err := loadTracked(db, "update", name)
+			if err != nil {
+				return err
+			}
+			targets = append(targets, *current)
+		}
+	}
+
+	if len(targets) == 0 {
+		cmd.Println("No modules are tracked")
+		return nil
+	}
+
+	results := updateAll(cmd.Context(), cmd.OutOrStderr(), db, targets, viper.GetInt("update.jobs"))
+
+	tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "NAME\tFROM\tTO\tSTATUS\tREASON")
+
+	failed := 0
+	for _, r := range results {
+		reason := ""
+		if r.Err != nil {
+			failed++
+			reason = r.Err.Error()
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", r.Name, r.From, r.To, r.Status, reason)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d updates failed", failed, len(results))
+	}
+	return nil
+}
+
+// updateAll runs checkUpdate for every target with at most jobs checks in
+// flight. Results are returned in the order of targets.
+func updateAll(ctx context.Context, out io.Writer, db *database.Database, targets []module.Module, jobs int) []updateResult {
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	results := make([]updateResult, len(targets))
+	sem := make(chan struct{}, jobs)
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+
+	for i := range targets {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			current := &targets[i]
+			m, err := module.NewModule(ctx, afs, "go")
+			if err != nil {
+				results[i] = updateResult{Name: current.Name, From: current.Version, Status: statusFailed, Err: err}
+			} else {
+				// Per module progress would interleave, only the outcome is reported.
+				results[i] = checkUpdate(ctx, io.Discard, db, m, current)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			_, _ = fmt.Fprintf(out, "Checked %s: %s\n", current.Name, results[i].Status)
+		}(i)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// checkUpdate installs the newest upstream version of current allowed by its
+// recorded query when it is newer than the recorded version.
+func checkUpdate(ctx context.Context, out io.Writer, db *database.Database, m *module.Module, current *module.Module) updateResult {
+	result := updateResult{Name: current.Name, From: current.Version, To: current.Version}
+
+	// Loading the record makes upgrade and patch queries relative to the installed version.
+	if err := m.Load(db, current.Name); err != nil {
+		result.Status, result.Err = statusFailed, err
+		return result
+	}
+
+	query := current.UpdateQuery()
+
+	_, _ = fmt.Fprintln(out, "Checking for updates:", current.Name)
+	target, err := m.ResolveVersion(query)
+	if err != nil {
+		result.Status, result.Err = statusFailed, err
+		return result
+	}
+
+	if semver.Compare(target, current.Version) <= 0 {
+		_, _ = fmt.Fprintf(out, "Module is already up to date: %s@%s\n", current.Name, current.Version)
+		result.Status = statusCurrent
+		return result
+	}
+
+	result.To = target
+	_, _ = fmt.Fprintf(out, "Updating %s from %s to %s\n", current.Name, current.Version, target)
+	if err := Install(ctx, out, db, m, fmt.Sprintf("%s@%s", current.Name, query)); err != nil {
+		result.Status, result.Err = statusFailed, err
+		return result
+	}
+
+	_, _ = fmt.Fprintln(out, "Module is updated successfully:", m.Name)
+	result.Status = statusUpgraded
+	return result
+}
@@ -0,0 +1,123 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"github.com/inovacc/goinstall/internal/database"
+	"github.com/inovacc/goinstall/internal/module"
+	"github.com/inovacc/goinstall/internal/shim"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+)
+
+// Use selects the version of a module that runs. By default the stored
+// version becomes the global default; with --local it is pinned in the
+// version file of the current directory instead and the GOBIN entry of the
+// module becomes a shim, which picks the version per directory. --shim and
+// --shim=false switch the entry between a shim and a plain link.
+func Use(cmd *cobra.Command, args []string) error {
+	afs = afero.NewOsFs()
+
+	local, _ := cmd.Flags().GetBool("local")
+	enableShim, _ := cmd.Flags().GetBool("shim")
+	setShim := cmd.Flags().Changed("shim")
+
+	_, version, _ := strings.Cut(args[0], "@")
+	if version != "" && !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	if version == "" && (local || !setShim) {
+		return fmt.Errorf("a version is required: %s use <module>@<version>", cmd.Root().Name())
+	}
+	if local && setShim && !enableShim {
+		return fmt.Errorf("--local needs the shim, it cannot be used with --shim=false")
+	}
+
+	db, err := database.NewDatabase(cmd.Context(), afs)
+	if err != nil {
+		return err
+	}
+	defer func(db *database.Database) {
+		cobra.CheckErr(db.Close())
+	}(db)
+
+	current, err := loadTracked(db, "use", args[0])
+	if err != nil {
+		return err
+	}
+
+	if version != "" {
+		var target module.Module
+		err := target.LoadVersion(db, current.Name, version)
+		if err != nil && !errors.Is(err, module.ErrNotTracked) {
+			return err
+		}
+		if err != nil || !target.Stored(afs) {
+			return fmt.Errorf("%s@%s is not installed, run: %s %s@%s", current.Name, version, cmd.Root().Name(), current.Name, version)
+		}
+	}
+
+	if local {
+		dir, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		path, err := shim.Pin(afs, dir, current.Name, version)
+		if err != nil {
+			return fmt.Errorf("failed to pin %s: %w", current.Name, err)
+		}
+		cmd.Printf("Pinned %s@%s in %s\n", current.Name, version, path)
+		setShim, enableShim = true, true
+	}
+
+	if setShim {
+		if err := switchShim(cmd, db, current.Name, enableShim); err != nil {
+			return err
+		}
+	}
+
+	if version != "" && !local && version != current.Version {
+		return activate(cmd, db, current, version, "use")
+	}
+	return nil
+}
+
+// switchShim turns the GOBIN entry of the module called name into a shim or
+// back into a link to its stored binary.
+func switchShim(cmd *cobra.Command, db *database.Database, name string, enabled bool) error {
+	m, err := module.NewModule(cmd.Context(), afs, "go")
+	if err != nil {
+		return err
+	}
+	if err := m.Load(db, name); err != nil {
+		return err
+	}
+	if m.Shim, err = module.ShimEnabled(db, name); err != nil {
+		return err
+	}
+	if m.Shim == enabled {
+		return nil
+	}
+	if !m.Stored(afs) {
+		return fmt.Errorf("%s@%s predates the store, reinstall it first", m.Name, m.Version)
+	}
+
+	if err := m.SetShim(db, enabled); err != nil {
+		return err
+	}
+	if err := m.Relink(); err != nil {
+		return err
+	}
+	if err := m.Report(db); err != nil {
+		return err
+	}
+
+	if enabled {
+		cmd.Printf("%s is now a shim for %s\n", m.BinaryPath(), m.Name)
+	} else {
+		cmd.Printf("%s now runs %s@%s\n", m.BinaryPath(), m.Name, m.Version)
+	}
+	return nil
+}
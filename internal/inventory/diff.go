@@ -0,0 +1,168 @@
+package inventory
+
+import (
+	"fmt"
+	"github.com/inovacc/goinstall/internal/database"
+	"github.com/inovacc/goinstall/internal/module"
+	"github.com/inovacc/goinstall/internal/printer"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	KindTool       = "tool"
+	KindTags       = "tags"
+	KindAlias      = "alias"
+	KindDependency = "dependency"
+)
+
+var afs afero.Fs
+
+// Difference is one way a tool differs between two inventories. Name is the
+// dependency for dependency drift. Left or Right is empty when the tool or
+// dependency is missing on that side.
+type Difference struct {
+	Tool  string `json:"tool"`
+	Kind  string `json:"kind"`
+	Name  string `json:"name,omitempty"`
+	Left  string `json:"left"`
+	Right string `json:"right"`
+}
+
+// Diff compares the inventory in the first file with the one in the second
+// file, or with the modules installed locally.
+func Diff(cmd *cobra.Command, args []string) error {
+	afs = afero.NewOsFs()
+
+	left, err := Load(afs, args[0])
+	if err != nil {
+		return err
+	}
+
+	var right *Inventory
+	if len(args) > 1 {
+		if right, err = Load(afs, args[1]); err != nil {
+			return err
+		}
+	} else {
+		db, err := database.NewDatabase(cmd.Context(), afs)
+		if err != nil {
+			return err
+		}
+		defer func(db *database.Database) {
+			cobra.CheckErr(db.Close())
+		}(db)
+
+		if right, err = FromDatabase(db); err != nil {
+			return err
+		}
+	}
+
+	format, _ := cmd.Flags().GetString("output")
+	diffs := Compare(left, right)
+	if len(diffs) == 0 && format == printer.FormatTable {
+		cmd.Println("No differences")
+		return nil
+	}
+
+	return printer.Print(cmd.OutOrStdout(), format, diffs, func(tw *tabwriter.Writer) {
+		_, _ = fmt.Fprintln(tw, "TOOL\tKIND\tNAME\tLEFT\tRIGHT")
+		for _, d := range diffs {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", d.Tool, d.Kind, orNone(d.Name), orNone(d.Left), orNone(d.Right))
+		}
+	})
+}
+
+// Compare lists the tools present on one side only, and for tools on both
+// sides the differences in version, build settings and dependency versions.
+// Tools are sorted by name, dependencies follow their tool.
+func Compare(left, right *Inventory) []Difference {
+	l, r := byName(left), byName(right)
+
+	names := make([]string, 0, len(l)+len(r))
+	for name := range l {
+		names = append(names, name)
+	}
+	for name := range r {
+		if _, ok := l[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	var diffs []Difference
+	for _, name := range names {
+		lm, inLeft := l[name]
+		rm, inRight := r[name]
+
+		switch {
+		case !inLeft:
+			diffs = append(diffs, Difference{Tool: name, Kind: KindTool, Right: rm.Version})
+			continue
+		case !inRight:
+			diffs = append(diffs, Difference{Tool: name, Kind: KindTool, Left: lm.Version})
+			continue
+		}
+
+		if lm.Version != rm.Version {
+			diffs = append(diffs, Difference{Tool: name, Kind: KindTool, Left: lm.Version, Right: rm.Version})
+		}
+		if lt, rt := strings.Join(module.SortedTags(lm.Tags), ","), strings.Join(module.SortedTags(rm.Tags), ","); lt != rt {
+			diffs = append(diffs, Difference{Tool: name, Kind: KindTags, Left: lt, Right: rt})
+		}
+		if lm.Alias != rm.Alias {
+			diffs = append(diffs, Difference{Tool: name, Kind: KindAlias, Left: lm.Alias, Right: rm.Alias})
+		}
+		diffs = append(diffs, dependencyDrift(name, lm, rm)...)
+	}
+	return diffs
+}
+
+// dependencyDrift compares the dependency versions of the same tool.
+func dependencyDrift(tool string, left, right *module.Module) []Difference {
+	versions := func(m *module.Module) map[string]string {
+		v := make(map[string]string, len(m.Dependencies))
+		for _, d := range m.Dependencies {
+			v[d.Name] = d.Version
+		}
+		return v
+	}
+	l, r := versions(left), versions(right)
+
+	names := make([]string, 0, len(l)+len(r))
+	for name := range l {
+		names = append(names, name)
+	}
+	for name := range r {
+		if _, ok := l[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	var diffs []Difference
+	for _, name := range names {
+		if l[name] != r[name] {
+			diffs = append(diffs, Difference{Tool: tool, Kind: KindDependency, Name: name, Left: l[name], Right: r[name]})
+		}
+	}
+	return diffs
+}
+
+func byName(inv *Inventory) map[string]*module.Module {
+	mods := make(map[string]*module.Module, len(inv.Modules))
+	for i := range inv.Modules {
+		mods[inv.Modules[i].Name] = &inv.Modules[i]
+	}
+	return mods
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,54 @@
+package inventory
+
+import (
+	"github.com/inovacc/goinstall/internal/module"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	left := &Inventory{Modules: []module.Module{
+		{Name: "mvdan.cc/gofumpt", Version: "v0.8.0", Dependencies: []module.Dependency{
+			{Name: "golang.org/x/mod", Version: "v0.20.0"},
+			{Name: "golang.org/x/sync", Version: "v0.8.0"},
+			{Name: "golang.org/x/tools", Version: "v0.24.0"},
+		}},
+		{Name: "golang.org/x/tools/cmd/stringer", Version: "v0.36.0", Tags: []string{"netgo"}},
+		{Name: "github.com/rakyll/hey", Version: "v0.1.4"},
+		{Name: "github.com/go-delve/delve/cmd/dlv", Version: "v1.25.1", Tags: []string{"osusergo", "netgo"}},
+	}}
+	right := &Inventory{Modules: []module.Module{
+		{Name: "mvdan.cc/gofumpt", Version: "v0.9.2", Dependencies: []module.Dependency{
+			{Name: "golang.org/x/mod", Version: "v0.27.0"},
+			{Name: "golang.org/x/sync", Version: "v0.8.0"},
+			{Name: "golang.org/x/sys", Version: "v0.35.0"},
+		}},
+		{Name: "golang.org/x/tools/cmd/stringer", Version: "v0.36.0", Alias: "stringer-x"},
+		{Name: "github.com/inovacc/ksuid/cmd/ksuid", Version: "v0.1.0"},
+		{Name: "github.com/go-delve/delve/cmd/dlv", Version: "v1.25.1", Tags: []string{"netgo", "osusergo"}},
+	}}
+
+	want := []Difference{
+		{Tool: "github.com/inovacc/ksuid/cmd/ksuid", Kind: KindTool, Right: "v0.1.0"},
+		{Tool: "github.com/rakyll/hey", Kind: KindTool, Left: "v0.1.4"},
+		{Tool: "golang.org/x/tools/cmd/stringer", Kind: KindTags, Left: "netgo"},
+		{Tool: "golang.org/x/tools/cmd/stringer", Kind: KindAlias, Right: "stringer-x"},
+		{Tool: "mvdan.cc/gofumpt", Kind: KindTool, Left: "v0.8.0", Right: "v0.9.2"},
+		{Tool: "mvdan.cc/gofumpt", Kind: KindDependency, Name: "golang.org/x/mod", Left: "v0.20.0", Right: "v0.27.0"},
+		{Tool: "mvdan.cc/gofumpt", Kind: KindDependency, Name: "golang.org/x/sys", Right: "v0.35.0"},
+		{Tool: "mvdan.cc/gofumpt", Kind: KindDependency, Name: "golang.org/x/tools", Left: "v0.24.0"},
+	}
+
+	got := Compare(left, right)
+	if len(got) != len(want) {
+		t.Fatalf("expected %d differences but got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("difference %d: expected %+v but got %+v", i, want[i], got[i])
+		}
+	}
+
+	if diffs := Compare(left, left); len(diffs) != 0 {
+		t.Fatalf("expected no differences but got %+v", diffs)
+	}
+}
@@ -0,0 +1,59 @@
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/inovacc/goinstall/internal/database"
+	"github.com/inovacc/goinstall/internal/module"
+	"github.com/spf13/afero"
+	"io"
+	"time"
+)
+
+// FormatVersion is the version of the inventory document, bumped on
+// incompatible changes.
+const FormatVersion = 1
+
+// Inventory is every module installed on a machine, as written by
+// goinstall export.
+type Inventory struct {
+	Version  int             `json:"version"`
+	Exported time.Time       `json:"exported"`
+	Modules  []module.Module `json:"modules"`
+}
+
+// FromDatabase returns the inventory of the modules installed according to db.
+func FromDatabase(db *database.Database) (*Inventory, error) {
+	mods, err := module.LoadInstalled(db)
+	if err != nil {
+		return nil, err
+	}
+	if mods == nil {
+		mods = []module.Module{}
+	}
+	return &Inventory{Version: FormatVersion, Exported: time.Now().UTC().Truncate(time.Second), Modules: mods}, nil
+}
+
+// Load reads the inventory document at path.
+func Load(fs afero.Fs, path string) (*Inventory, error) {
+	data, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var inv Inventory
+	if err := json.Unmarshal(data, &inv); err != nil {
+		return nil, fmt.Errorf("failed to decode inventory %s: %w", path, err)
+	}
+	if inv.Version > FormatVersion {
+		return nil, fmt.Errorf("inventory %s has format version %d, this goinstall reads up to %d", path, inv.Version, FormatVersion)
+	}
+	return &inv, nil
+}
+
+// Write writes the inventory as indented JSON.
+func (inv *Inventory) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(inv)
+}
@@ -0,0 +1,74 @@
+package inventory
+
+import (
+	"bytes"
+	"context"
+	"github.com/inovacc/goinstall/internal/database"
+	"github.com/inovacc/goinstall/internal/module"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestInventory_RoundTrip(t *testing.T) {
+	viper.Set("installPath", filepath.Join(t.TempDir(), "modules.db"))
+
+	db, err := database.NewDatabase(context.TODO(), afero.NewOsFs())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(db *database.Database) {
+		_ = db.Close()
+	}(db)
+
+	mod := &module.Module{
+		Name:    "mvdan.cc/gofumpt",
+		Version: "v0.9.2",
+		Query:   "^0.9",
+		Tags:    []string{"netgo"},
+		Alias:   "gofumpt9",
+		Time:    time.Now(),
+		Dependencies: []module.Dependency{
+			{Name: "golang.org/x/mod", Version: "v0.27.0"},
+		},
+	}
+	if err := mod.Report(db); err != nil {
+		t.Fatal(err)
+	}
+
+	inv, err := FromDatabase(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := inv.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "inventory.json", buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(fs, "inventory.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(loaded.Modules) != 1 {
+		t.Fatalf("expected 1 module but got %d", len(loaded.Modules))
+	}
+	m := loaded.Modules[0]
+	if m.Name != mod.Name || m.Query != "^0.9" || m.Alias != "gofumpt9" || len(m.Tags) != 1 || len(m.Dependencies) != 1 {
+		t.Fatalf("unexpected module %+v", m)
+	}
+
+	if err := afero.WriteFile(fs, "future.json", []byte(`{"version": 99, "modules": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(fs, "future.json"); err == nil {
+		t.Fatal("expected a newer format version to be rejected")
+	}
+}
@@ -0,0 +1,130 @@
+package license
+
+import (
+	"fmt"
+	"github.com/spf13/afero"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	// Unknown is the license of a module whose license file is not recognized.
+	Unknown = "unknown"
+	// None is the license of a module without a license file.
+	None = "none"
+)
+
+// rule recognizes a license by its title, or the opening phrase of a text
+// without one, and by phrases that follow it. Texts quote other licenses, the
+// GPL names the AGPL and the MPL lists the GPLs as Secondary Licenses, so a
+// title only counts where the license starts, see Classify.
+type rule struct {
+	id      string
+	titles  []string
+	phrases []string
+}
+
+var rules = []rule{
+	{"AGPL-3.0", []string{"gnu affero general public license version 3"}, nil},
+	{"LGPL-3.0", []string{"gnu lesser general public license version 3"}, nil},
+	{"LGPL-2.1", []string{"gnu lesser general public license version 2.1"}, nil},
+	{"LGPL-2.0", []string{"gnu library general public license version 2"}, nil},
+	{"GPL-3.0", []string{"gnu general public license version 3"}, nil},
+	{"GPL-2.0", []string{"gnu general public license version 2"}, nil},
+	{"MPL-2.0", []string{"mozilla public license version 2.0", "mozilla public license, version 2.0", "mozilla public license, v. 2.0"}, nil},
+	{"EPL-2.0", []string{"eclipse public license - v 2.0"}, nil},
+	{"Apache-2.0", []string{"apache license version 2.0", "apache license, version 2.0"}, nil},
+	{"BSD-3-Clause", []string{"redistribution and use in source and binary forms"}, []string{"neither the name"}},
+	{"BSD-3-Clause", []string{"redistribution and use in source and binary forms"}, []string{"names of its contributors may be used"}},
+	{"BSD-2-Clause", []string{"redistribution and use in source and binary forms"}, nil},
+	{"MIT", []string{"permission is hereby granted, free of charge"}, nil},
+	{"ISC", []string{"permission to use, copy, modify, and"}, []string{"distribute this software for any purpose with or without fee"}},
+	{"BSL-1.0", []string{"boost software license - version 1.0"}, nil},
+	{"Unlicense", []string{"this is free and unencumbered software released into the public domain"}, nil},
+	{"CC0-1.0", []string{"cc0 1.0 universal"}, nil},
+}
+
+// Classify returns the SPDX identifier of the license text, or Unknown. The
+// license whose title comes first wins; a file starting with a copyright line
+// or a notice before the license text is still classified by that text.
+func Classify(text string) string {
+	text = strings.ToLower(strings.Join(strings.Fields(text), " "))
+
+	id, first := Unknown, len(text)
+	for _, r := range rules {
+		for _, title := range r.titles {
+			i := strings.Index(text, title)
+			if i >= 0 && i < first && containsAll(text[i:], r.phrases) {
+				id, first = r.id, i
+			}
+		}
+	}
+	return id
+}
+
+// Detect classifies the license files at the top of the module directory
+// dir, such as LICENSE, LICENSE-APACHE or COPYING. Several licenses are
+// joined with AND, as a module may ship code under each of them.
+func Detect(fs afero.Fs, dir string) (string, error) {
+	entries, err := afero.ReadDir(fs, dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read module directory: %w", err)
+	}
+
+	var ids []string
+	for _, e := range entries {
+		if e.IsDir() || !isLicenseFile(e.Name()) {
+			continue
+		}
+
+		data, err := afero.ReadFile(fs, filepath.Join(dir, e.Name()))
+		if err != nil {
+			return "", fmt.Errorf("failed to read license file: %w", err)
+		}
+		if id := Classify(string(data)); !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) == 0 {
+		return None, nil
+	}
+	slices.Sort(ids)
+	return strings.Join(ids, " AND "), nil
+}
+
+// Denied returns the licenses of expr that match an entry of deny. An entry
+// matches a license with the same identifier, ignoring case, or any version
+// of it: AGPL matches AGPL-3.0 but GPL does not match LGPL-2.1.
+func Denied(expr string, deny []string) []string {
+	var denied []string
+	for _, id := range strings.Split(expr, " AND ") {
+		for _, d := range deny {
+			if strings.EqualFold(id, d) || strings.HasPrefix(strings.ToLower(id), strings.ToLower(d)+"-") {
+				denied = append(denied, id)
+				break
+			}
+		}
+	}
+	return denied
+}
+
+func isLicenseFile(name string) bool {
+	name = strings.ToLower(name)
+	for _, prefix := range []string{"license", "licence", "copying", "unlicense"} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAll(text string, phrases []string) bool {
+	for _, p := range phrases {
+		if !strings.Contains(text, p) {
+			return false
+		}
+	}
+	return true
+}
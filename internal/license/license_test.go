@@ -0,0 +1,128 @@
+package license
+
+import (
+	"github.com/spf13/afero"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+const (
+	mitText = `MIT License
+
+Copyright (c) 2024 Example
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal`
+
+	bsdText = `Copyright (c) 2009 The Go Authors. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+   * Neither the name of Google LLC nor the names of its
+contributors may be used to endorse or promote products derived from`
+
+	agplText = `                    GNU AFFERO GENERAL PUBLIC LICENSE
+                       Version 3, 19 November 2007
+
+ Developers that use our General Public Licenses protect your rights
+ ... the GNU General Public License ...`
+)
+
+func TestClassify(t *testing.T) {
+	// Full texts quote other licenses: the GPL names the AGPL, the MPL lists
+	// the AGPL and the LGPL builds on the GPL.
+	for _, id := range []string{"GPL-3.0", "LGPL-3.0", "MPL-2.0", "Apache-2.0"} {
+		data, err := os.ReadFile(filepath.Join("testdata", id))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := Classify(string(data)); got != id {
+			t.Errorf("Classify(testdata/%s) = %s, want %s", id, got, id)
+		}
+		notice := "Copyright (c) 2024 Example\n\n" + string(data)
+		if got := Classify(notice); got != id {
+			t.Errorf("Classify(copyright + testdata/%s) = %s, want %s", id, got, id)
+		}
+	}
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{mitText, "MIT"},
+		{bsdText, "BSD-3-Clause"},
+		{agplText, "AGPL-3.0"},
+		{"Apache License\n  Version 2.0, January 2004\n http://www.apache.org/licenses/", "Apache-2.0"},
+		{"GNU LESSER GENERAL PUBLIC LICENSE\n Version 2.1, February 1999", "LGPL-2.1"},
+		{"GNU GENERAL PUBLIC LICENSE\n Version 2, June 1991", "GPL-2.0"},
+		{"Mozilla Public License Version 2.0\n==================================", "MPL-2.0"},
+		{"Licensed under the Apache License, Version 2.0 (the \"License\");", "Apache-2.0"},
+		{"Copyright 2024 Example\n\nPermission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted", "ISC"},
+		{"All rights reserved.", Unknown},
+	}
+
+	for _, tt := range tests {
+		if got := Classify(tt.text); got != tt.want {
+			t.Errorf("Classify(%.30q) = %s, want %s", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	files := map[string]string{
+		"/mod/dual@v1.0.0/LICENSE-MIT":   mitText,
+		"/mod/dual@v1.0.0/LICENSE.bsd":   bsdText,
+		"/mod/dual@v1.0.0/README.md":     agplText,
+		"/mod/dual@v1.0.0/sub/COPYING":   agplText,
+		"/mod/plain@v1.0.0/main.go":      "package main",
+		"/mod/copying@v1.0.0/COPYING.md": agplText,
+	}
+	for path, text := range files {
+		if err := afero.WriteFile(fs, path, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for dir, want := range map[string]string{
+		"/mod/dual@v1.0.0":    "BSD-3-Clause AND MIT",
+		"/mod/plain@v1.0.0":   None,
+		"/mod/copying@v1.0.0": "AGPL-3.0",
+	} {
+		got, err := Detect(fs, dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("Detect(%s) = %s, want %s", dir, got, want)
+		}
+	}
+
+	if _, err := Detect(fs, "/mod/missing"); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
+
+func TestDenied(t *testing.T) {
+	tests := []struct {
+		expr string
+		deny []string
+		want []string
+	}{
+		{"AGPL-3.0", []string{"agpl"}, []string{"AGPL-3.0"}},
+		{"LGPL-2.1", []string{"GPL"}, nil},
+		{"GPL-2.0", []string{"GPL"}, []string{"GPL-2.0"}},
+		{"Apache-2.0 AND GPL-3.0", []string{"GPL-3.0"}, []string{"GPL-3.0"}},
+		{"unknown", []string{"unknown", "none"}, []string{"unknown"}},
+		{"MIT", []string{"AGPL", "GPL"}, nil},
+	}
+
+	for _, tt := range tests {
+		if got := Denied(tt.expr, tt.deny); !slices.Equal(got, tt.want) {
+			t.Errorf("Denied(%q, %v) = %v, want %v", tt.expr, tt.deny, got, tt.want)
+		}
+	}
+}
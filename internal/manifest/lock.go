@@ -0,0 +1,102 @@
+package manifest
+
+import (
+	"bytes"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+)
+
+// LockName is the lockfile written next to the manifest.
+const LockName = "goinstall.lock"
+
+const lockHeader = "# Code generated by goinstall lock. DO NOT EDIT.\n"
+
+// LockedTool pins a manifest tool to the version it resolved to.
+type LockedTool struct {
+	Module string `yaml:"module" json:"module"`
+	// Query is the manifest version the tool was locked for.
+	Query string `yaml:"query,omitempty" json:"query,omitempty"`
+	// Root is the module providing the package and Sum its h1: hash, as
+	// recorded in go.sum. The go.sum of the module pins the dependencies in
+	// turn, so a matching Sum gives a byte-identical build.
+	Root         string             `yaml:"root" json:"root"`
+	Version      string             `yaml:"version" json:"version"`
+	Sum          string             `yaml:"sum" json:"sum"`
+	Tags         []string           `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Alias        string             `yaml:"alias,omitempty" json:"alias,omitempty"`
+	Dependencies []LockedDependency `yaml:"dependencies,omitempty" json:"dependencies,omitempty"`
+}
+
+// LockedDependency is a module version the locked tool builds with.
+type LockedDependency struct {
+	Module  string `yaml:"module" json:"module"`
+	Version string `yaml:"version" json:"version"`
+}
+
+// Lock is the content of a lockfile.
+type Lock struct {
+	Tools []LockedTool `yaml:"tools" json:"tools"`
+}
+
+// LockPath returns the lockfile belonging to the manifest at path.
+func LockPath(manifestPath string) string {
+	return filepath.Join(filepath.Dir(manifestPath), LockName)
+}
+
+// LoadLock reads the lockfile at path.
+func LoadLock(path string) (*Lock, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lockfile, run goinstall lock first: %w", err)
+	}
+
+	var l Lock
+	if err := yaml.Unmarshal(data, &l); err != nil {
+		return nil, fmt.Errorf("failed to decode lockfile %s: %w", path, err)
+	}
+	return &l, nil
+}
+
+// Save writes the lockfile to path.
+func (l *Lock) Save(path string) error {
+	var buf bytes.Buffer
+	buf.WriteString(lockHeader)
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(l); err != nil {
+		return fmt.Errorf("failed to encode lockfile: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// Pin returns a copy of m whose tools ask for exactly the locked versions,
+// with the locked entry of each tool by module path. It fails when the lock
+// is stale: a tool is missing from it, or was locked for another version
+// query.
+func (l *Lock) Pin(m *Manifest) (*Manifest, map[string]LockedTool, error) {
+	locked := make(map[string]LockedTool, len(l.Tools))
+	for _, t := range l.Tools {
+		locked[t.Module] = t
+	}
+
+	pinned := &Manifest{Path: m.Path, Tools: make([]Tool, len(m.Tools))}
+	for i, t := range m.Tools {
+		lt, ok := locked[t.Module]
+		switch {
+		case !ok:
+			return nil, nil, fmt.Errorf("%s is not locked, run goinstall lock", t.Module)
+		case lt.Query != t.Version:
+			return nil, nil, fmt.Errorf("%s was locked for version %q, not %q, run goinstall lock", t.Module, lt.Query, t.Version)
+		}
+
+		t.Version = lt.Version
+		pinned.Tools[i] = t
+	}
+	return pinned, locked, nil
+}
@@ -0,0 +1,52 @@
+package manifest
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLock_SaveAndPin(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LockName)
+	lock := &Lock{Tools: []LockedTool{{
+		Module:  "mvdan.cc/gofumpt",
+		Query:   "^0.9",
+		Root:    "mvdan.cc/gofumpt",
+		Version: "v0.9.2",
+		Sum:     "h1:zsEMWL8SVKGHNztrx6uZrXdp7AX8r421Vvp23sz7ik4=",
+		Dependencies: []LockedDependency{
+			{Module: "golang.org/x/mod", Version: "v0.27.0"},
+		},
+	}}}
+	if err := lock.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadLock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Tools) != 1 || loaded.Tools[0].Sum != lock.Tools[0].Sum || len(loaded.Tools[0].Dependencies) != 1 {
+		t.Fatalf("unexpected lock %+v", loaded)
+	}
+
+	mf := &Manifest{Tools: []Tool{{Module: "mvdan.cc/gofumpt", Version: "^0.9", Alias: "gofumpt9"}}}
+	pinned, locked, err := loaded.Pin(mf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pinned.Tools[0].Version != "v0.9.2" || pinned.Tools[0].Alias != "gofumpt9" || mf.Tools[0].Version != "^0.9" {
+		t.Fatalf("unexpected pinned manifest %+v", pinned.Tools)
+	}
+	if locked["mvdan.cc/gofumpt"].Root != "mvdan.cc/gofumpt" {
+		t.Fatalf("unexpected locked tools %+v", locked)
+	}
+
+	for _, stale := range []*Manifest{
+		{Tools: []Tool{{Module: "mvdan.cc/gofumpt", Version: "v0.8.0"}}},
+		{Tools: []Tool{{Module: "golang.org/x/tools/cmd/stringer"}}},
+	} {
+		if _, _, err := loaded.Pin(stale); err == nil {
+			t.Fatalf("expected %+v to be stale", stale.Tools)
+		}
+	}
+}
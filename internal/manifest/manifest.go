@@ -0,0 +1,103 @@
+package manifest
+
+import (
+	"errors"
+	"fmt"
+	"github.com/inovacc/goinstall/internal/module"
+	"github.com/spf13/viper"
+	"slices"
+	"strings"
+)
+
+// DefaultName is the manifest file name looked up in the working directory,
+// with any extension viper can read, such as goinstall.yaml or goinstall.toml.
+const DefaultName = "goinstall"
+
+// Tool is a module the manifest wants installed.
+type Tool struct {
+	// Module is the package path to install.
+	Module string `mapstructure:"module" json:"module"`
+	// Version is a version query as accepted on the command line: an exact
+	// version, a range such as ^1.4 or a branch. Empty accepts any
+	// installed version and installs the latest.
+	Version string `mapstructure:"version" json:"version,omitempty"`
+	// Tags are the build tags passed to go install.
+	Tags []string `mapstructure:"tags" json:"tags,omitempty"`
+	// Alias is the binary name, replacing the one go install picks.
+	Alias string `mapstructure:"alias" json:"alias,omitempty"`
+}
+
+// Manifest is the declared set of tools of a machine or team.
+type Manifest struct {
+	Path  string `mapstructure:"-" json:"-"`
+	Tools []Tool `mapstructure:"tools" json:"tools"`
+}
+
+// Load reads the manifest at path, or looks up goinstall.* in the working
+// directory when path is empty.
+func Load(path string) (*Manifest, error) {
+	v := viper.New()
+	if path != "" {
+		v.SetConfigFile(path)
+	} else {
+		v.AddConfigPath(".")
+		v.SetConfigName(DefaultName)
+	}
+
+	if err := v.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if errors.As(err, &notFound) {
+			return nil, fmt.Errorf("no %s.yaml or %s.toml manifest in the working directory", DefaultName, DefaultName)
+		}
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var m Manifest
+	if err := v.Unmarshal(&m); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest %s: %w", v.ConfigFileUsed(), err)
+	}
+	m.Path = v.ConfigFileUsed()
+
+	if err := m.normalize(); err != nil {
+		return nil, fmt.Errorf("%s: %w", m.Path, err)
+	}
+	return &m, nil
+}
+
+// normalize cleans the module paths the way the command line does and
+// rejects entries goinstall cannot act on.
+func (m *Manifest) normalize() error {
+	seen := make(map[string]struct{}, len(m.Tools))
+	aliases := make(map[string]string, len(m.Tools))
+
+	for i := range m.Tools {
+		t := &m.Tools[i]
+		if t.Module == "" {
+			return fmt.Errorf("tool %d has no module", i+1)
+		}
+
+		// A version may also be given inline as module@version.
+		if _, version, ok := strings.Cut(t.Module, "@"); ok && t.Version == "" {
+			t.Version = version
+		}
+		t.Module = module.ParseName(strings.TrimSpace(t.Module))
+
+		if _, ok := seen[t.Module]; ok {
+			return fmt.Errorf("%s is listed twice", t.Module)
+		}
+		seen[t.Module] = struct{}{}
+
+		if t.Alias != "" {
+			if t.Alias == "." || t.Alias == ".." || strings.ContainsAny(t.Alias, `/\`) {
+				return fmt.Errorf("alias %q of %s must be a file name", t.Alias, t.Module)
+			}
+			if other, ok := aliases[t.Alias]; ok {
+				return fmt.Errorf("alias %q is used by %s and %s", t.Alias, other, t.Module)
+			}
+			aliases[t.Alias] = t.Module
+		}
+
+		t.Tags = slices.DeleteFunc(t.Tags, func(tag string) bool { return strings.TrimSpace(tag) == "" })
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func writeManifest(t *testing.T, name, data string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	yaml := writeManifest(t, "goinstall.yaml", `
+tools:
+  - module: https://github.com/inovacc/ksuid/cmd/ksuid
+    version: ^0.1
+    tags: [netgo, ""]
+    alias: ksuid-dev
+  - module: mvdan.cc/gofumpt@v0.8.0
+`)
+	toml := writeManifest(t, "goinstall.toml", `
+[[tools]]
+module = "https://github.com/inovacc/ksuid/cmd/ksuid"
+version = "^0.1"
+tags = ["netgo", ""]
+alias = "ksuid-dev"
+
+[[tools]]
+module = "mvdan.cc/gofumpt@v0.8.0"
+`)
+
+	for _, path := range []string{yaml, toml} {
+		m, err := Load(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(m.Tools) != 2 {
+			t.Fatalf("%s: expected 2 tools but got %+v", path, m.Tools)
+		}
+
+		ksuid := m.Tools[0]
+		if ksuid.Module != "github.com/inovacc/ksuid/cmd/ksuid" || ksuid.Version != "^0.1" || ksuid.Alias != "ksuid-dev" {
+			t.Fatalf("%s: unexpected tool %+v", path, ksuid)
+		}
+		if !slices.Equal(ksuid.Tags, []string{"netgo"}) {
+			t.Fatalf("%s: unexpected tags %v", path, ksuid.Tags)
+		}
+		if gofumpt := m.Tools[1]; gofumpt.Module != "mvdan.cc/gofumpt" || gofumpt.Version != "v0.8.0" {
+			t.Fatalf("%s: unexpected tool %+v", path, gofumpt)
+		}
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"twice":  "tools:\n  - module: mvdan.cc/gofumpt\n  - module: mvdan.cc/gofumpt@v0.8.0\n",
+		"alias":  "tools:\n  - module: mvdan.cc/gofumpt\n    alias: ../gofumpt\n",
+		"shared": "tools:\n  - module: mvdan.cc/gofumpt\n    alias: fmt\n  - module: golang.org/x/tools/cmd/goimports\n    alias: fmt\n",
+		"empty":  "tools:\n  - version: v1.0.0\n",
+	}
+
+	for name, data := range tests {
+		if _, err := Load(writeManifest(t, "goinstall.yaml", data)); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+	}
+
+	if _, err := Load(""); err == nil || !strings.Contains(err.Error(), "manifest") {
+		t.Fatalf("expected a missing manifest error but got %v", err)
+	}
+}
@@ -1,11 +1,23 @@
 package module
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/spf13/afero"
+	"io"
+	"os"
 	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
 )
 
+var majorVersionSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
 func validGoBinary(name string) error {
 	if err := exec.Command(name).Run(); err != nil {
 		var exitErr *exec.ExitError
@@ -15,3 +27,77 @@ func validGoBinary(name string) error {
 	}
 	return nil
 }
+
+// BinDir returns the directory go install writes executables to: GOBIN
+// when set, the bin directory of GOPATH otherwise.
+func BinDir() string {
+	if gobin := os.Getenv("GOBIN"); gobin != "" {
+		return gobin
+	}
+
+	gopath := os.Getenv("GOPATH")
+	if gopath == "" {
+		gopath = filepath.Join(os.Getenv("HOME"), "go")
+	}
+	return filepath.Join(gopath, "bin")
+}
+
+// binaryName mirrors how go install names executables: the last element of
+// the package path, skipping a trailing major version suffix such as /v2.
+func binaryName(pkg string) string {
+	name := path.Base(pkg)
+	if majorVersionSuffix.MatchString(name) {
+		if parent := path.Dir(pkg); parent != "." {
+			name = path.Base(parent)
+		}
+	}
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// goEnv returns the effective value of the given go environment variables,
+// including the ones set with go env -w.
+func goEnv(name string, keys ...string) (map[string]string, error) {
+	out, err := exec.Command(name, append([]string{"env", "-json"}, keys...)...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("go env failed: %w", err)
+	}
+
+	env := make(map[string]string, len(keys))
+	if err := json.Unmarshal(out, &env); err != nil {
+		return nil, fmt.Errorf("decoding go env failed: %w", err)
+	}
+	return env, nil
+}
+
+// HashFile returns the SHA-256 checksum and size of the file at path.
+func HashFile(fs afero.Fs, path string) (string, int64, error) {
+	f, err := fs.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer func(f afero.File) {
+		_ = f.Close()
+	}(f)
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+// recordBinary stores the location, checksum and size of the binary
+// installed for m.
+func (m *Module) recordBinary(path string) error {
+	hash, size, err := HashFile(m.fs, path)
+	if err != nil {
+		return fmt.Errorf("failed to record installed binary: %w", err)
+	}
+
+	m.Binary, m.Hash, m.Size = path, hash, size
+	return nil
+}
@@ -0,0 +1,64 @@
+package module
+
+import (
+	"debug/buildinfo"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNoVersion is returned for binaries built from a local checkout, which
+// have no module version to update from.
+var ErrNoVersion = errors.New("binary was not built from a module version")
+
+// LoadBinary fills m from the build information embedded in the Go binary at
+// path: the package it was built from, the module version, the Go version and
+// the module dependencies, as go version -m reports them. The checksum of the
+// binary is recorded as for an install.
+func (m *Module) LoadBinary(path string) error {
+	info, err := buildinfo.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read build info: %w", err)
+	}
+
+	// Only go install of a module version records the module checksum,
+	// builds of a checkout may still carry a version stamped from VCS.
+	version := info.Main.Version
+	if version == "" || version == "(devel)" || info.Main.Sum == "" {
+		return ErrNoVersion
+	}
+
+	stat, err := m.fs.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	m.Name = info.Path
+	m.Version = version
+	m.GoVersion = info.GoVersion
+	m.Time = stat.ModTime().Truncate(time.Second)
+	m.Dependencies = make([]Dependency, 0, len(info.Deps)+1)
+
+	// go install lists the module providing the package among the
+	// dependencies unless it is the package itself, keep it that way.
+	if info.Main.Path != "" && info.Main.Path != info.Path {
+		m.Dependencies = append(m.Dependencies, Dependency{
+			Name:    info.Main.Path,
+			Version: version,
+			Hash:    m.hashModule(fmt.Sprintf("%s@%s", info.Main.Path, version)),
+		})
+	}
+
+	for _, d := range info.Deps {
+		dep := d
+		if d.Replace != nil {
+			dep = d.Replace
+		}
+		m.Dependencies = append(m.Dependencies, Dependency{
+			Name:    d.Path,
+			Version: dep.Version,
+			Hash:    m.hashModule(fmt.Sprintf("%s@%s", d.Path, dep.Version)),
+		})
+	}
+	return m.recordBinary(path)
+}
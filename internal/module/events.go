@@ -0,0 +1,37 @@
+package module
+
+import (
+	"fmt"
+	"github.com/inovacc/goinstall/internal/database"
+	"strings"
+	"time"
+)
+
+// RecordEvent appends an action taken on the module, such as an automatic
+// install, to the events table.
+func (m *Module) RecordEvent(db *database.Database, version, action, detail string) error {
+	query := `INSERT INTO events (module_name, version, action, detail, time) VALUES (?, ?, ?, ?, ?)`
+	if _, err := db.Exec(query, m.Name, version, action, detail, time.Now()); err != nil {
+		return fmt.Errorf("failed to record event: %w", err)
+	}
+	return nil
+}
+
+// HasEvent reports whether one of actions was recorded for version of the
+// module.
+func (m *Module) HasEvent(db *database.Database, version string, actions ...string) (bool, error) {
+	if len(actions) == 0 {
+		return false, nil
+	}
+	query := `SELECT EXISTS (SELECT 1 FROM events WHERE module_name = ? AND version = ? AND action IN (?` + strings.Repeat(", ?", len(actions)-1) + `))`
+	args := []any{m.Name, version}
+	for _, a := range actions {
+		args = append(args, a)
+	}
+
+	var found bool
+	if err := db.QueryRow(query, args...).Scan(&found); err != nil {
+		return false, fmt.Errorf("failed to look up events: %w", err)
+	}
+	return found, nil
+}
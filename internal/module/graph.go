@@ -0,0 +1,181 @@
+package module
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/inovacc/goinstall/internal/database"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+	"os/exec"
+	"strings"
+)
+
+// Edge is a requirement in the module graph: From at FromVersion requires To
+// at Version. The selected version of To may be higher.
+type Edge struct {
+	From        string `json:"from"`
+	FromVersion string `json:"fromVersion"`
+	To          string `json:"to"`
+	Version     string `json:"version"`
+}
+
+// extractGraph runs go mod graph in dir and keeps the requirements of the
+// selected version of every module, rooted at the installed package. The
+// scratch module itself is replaced by the package m installs.
+func (m *Module) extractGraph(ctx context.Context, dir string) ([]Edge, error) {
+	cmd := exec.CommandContext(ctx, m.goBinPath, "mod", "graph")
+	cmd.Dir = dir
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go mod graph failed: %w", err)
+	}
+
+	selected := map[string]string{m.Name: m.Version}
+	for _, d := range m.Dependencies {
+		selected[d.Name] = d.Version
+	}
+
+	var (
+		edges []Edge
+		root  Edge
+	)
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+
+		from, fromVersion, _ := strings.Cut(fields[0], "@")
+		to, version, _ := strings.Cut(fields[1], "@")
+		if to == "go" || to == "toolchain" {
+			continue
+		}
+
+		if from == dummyModuleName {
+			// The scratch module also lists the tool requirements as
+			// indirect, only the module providing the package is kept.
+			if (to == m.Name || strings.HasPrefix(m.Name, to+"/")) && len(to) > len(root.To) {
+				root = Edge{From: m.Name, FromVersion: m.Version, To: to, Version: version}
+			}
+			continue
+		}
+
+		if v, ok := selected[from]; !ok || v != fromVersion {
+			continue
+		}
+		edges = append(edges, Edge{From: from, FromVersion: fromVersion, To: to, Version: version})
+	}
+
+	if root.To != "" && root.To != m.Name {
+		edges = append([]Edge{root}, edges...)
+	}
+	return edges, nil
+}
+
+// linkDependencies fills the Dependencies of every dependency with its direct
+// requirements, at the version each one requires.
+func (m *Module) linkDependencies() {
+	requires := make(map[string][]Dependency)
+	for _, e := range m.Graph {
+		requires[e.From] = append(requires[e.From], Dependency{Name: e.To, Version: e.Version})
+	}
+
+	for i := range m.Dependencies {
+		m.Dependencies[i].Dependencies = requires[m.Dependencies[i].Name]
+	}
+}
+
+// ModuleRoot returns the path of the module providing the package m installs.
+func (m *Module) ModuleRoot() string {
+	for _, e := range m.Graph {
+		if e.From == m.Name && strings.HasPrefix(m.Name, e.To+"/") {
+			return e.To
+		}
+	}
+
+	root := m.Name
+	for _, d := range m.Dependencies {
+		if strings.HasPrefix(m.Name, d.Name+"/") && (root == m.Name || len(d.Name) > len(root)) {
+			root = d.Name
+		}
+	}
+	return root
+}
+
+// Requirements returns the module versions required by the go.mod of the
+// module providing m at version. Since Go 1.17 go.mod lists every module a
+// build of the tool uses at the version it selects; older files are rejected
+// as they cannot tell.
+func (m *Module) Requirements(version string) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(m.ctx, m.getTimeout())
+	defer cancel()
+
+	root := m.ModuleRoot()
+	data, err := m.proxy.Mod(ctx, root, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch go.mod of %s@%s: %w", root, version, err)
+	}
+
+	file, err := modfile.ParseLax("go.mod", data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse go.mod of %s@%s: %w", root, version, err)
+	}
+
+	if file.Go == nil || semver.Compare("v"+file.Go.Version, "v1.17") < 0 {
+		return nil, fmt.Errorf("go.mod of %s@%s does not list its full requirements", root, version)
+	}
+
+	requires := make(map[string]string, len(file.Require))
+	for _, r := range file.Require {
+		requires[r.Mod.Path] = r.Mod.Version
+	}
+	return requires, nil
+}
+
+// LoadGraph returns the requirement graph recorded for the module called name.
+func LoadGraph(db *database.Database, name string) ([]Edge, error) {
+	rows, err := db.Query(`
+		SELECT from_name, from_version, to_name, to_version
+		FROM dependency_edges
+		WHERE module_name = ?
+		ORDER BY from_name, to_name
+		`, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dependency graph: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	var edges []Edge
+	for rows.Next() {
+		var e Edge
+		if err := rows.Scan(&e.From, &e.FromVersion, &e.To, &e.Version); err != nil {
+			return nil, err
+		}
+		edges = append(edges, e)
+	}
+	return edges, rows.Err()
+}
+
+func (m *Module) reportGraph(tx *sql.Tx) error {
+	if _, err := tx.Exec(`DELETE FROM dependency_edges WHERE module_name = ?`, m.Name); err != nil {
+		return fmt.Errorf("failed to delete dependency graph: %w", err)
+	}
+
+	edgeStmt := `
+		INSERT INTO dependency_edges (module_name, from_name, from_version, to_name, to_version)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(module_name, from_name, to_name) DO UPDATE
+		SET from_version = excluded.from_version,
+			to_version = excluded.to_version
+		`
+	for _, e := range m.Graph {
+		if _, err := tx.Exec(edgeStmt, m.Name, e.From, e.FromVersion, e.To, e.Version); err != nil {
+			return fmt.Errorf("failed to insert dependency edge: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package module
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/inovacc/goinstall/internal/license"
+	"io"
+	"os/exec"
+)
+
+// extractLicenses classifies the license of the tool module and of every
+// dependency from their directory in the module cache, where go get left
+// them. A module go get only needed the go.mod of has no directory; it adds
+// no code to the binary and keeps an empty license.
+func (m *Module) extractLicenses(ctx context.Context, dir string) error {
+	cmd := exec.CommandContext(ctx, m.goBinPath, "list", "-m", "-json", "all")
+	cmd.Dir = dir
+
+	out, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("go list -m -json all failed: %w", err)
+	}
+
+	dirs := make(map[string]string)
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var mod struct {
+			Path string `json:"Path"`
+			Dir  string `json:"Dir"`
+		}
+		if err := dec.Decode(&mod); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return fmt.Errorf("decoding module list failed: %w", err)
+		}
+		dirs[mod.Path] = mod.Dir
+	}
+
+	detect := func(path string) (string, error) {
+		if dirs[path] == "" {
+			return "", nil
+		}
+		id, err := license.Detect(m.fs, dirs[path])
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", path, err)
+		}
+		return id, nil
+	}
+
+	if m.License, err = detect(m.ModuleRoot()); err != nil {
+		return err
+	}
+	for i := range m.Dependencies {
+		if m.Dependencies[i].License, err = detect(m.Dependencies[i].Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
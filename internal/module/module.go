@@ -5,42 +5,64 @@ import (
 	"context"
 	"crypto/sha256"
 	"database/sql"
+	"debug/buildinfo"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/inovacc/goinstall/internal/database"
+	"github.com/inovacc/goinstall/internal/goproxy"
 	"github.com/spf13/afero"
 	"golang.org/x/mod/semver"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
 const dummyModuleName = "dummy"
 
+// downloadTimeout bounds go mod download, which has to fetch the whole module
+// the first time it is seen rather than answer a lookup.
+const downloadTimeout = 5 * time.Minute
+
 type Module struct {
 	ctx          context.Context
 	fs           afero.Fs
 	goBinPath    string
+	proxy        *goproxy.Client
 	timeout      time.Duration
+	concurrency  int
 	Time         time.Time    `json:"time"`
 	Name         string       `json:"name"`
 	Hash         string       `json:"hash"`
 	Version      string       `json:"version"`
+	Query        string       `json:"query,omitempty"`
+	GoVersion    string       `json:"goVersion,omitempty"`
+	Binary       string       `json:"binary,omitempty"`
+	Size         int64        `json:"size,omitempty"`
+	Tags         []string     `json:"tags,omitempty"`
+	Alias        string       `json:"alias,omitempty"`
+	License      string       `json:"license,omitempty"`
+	Shim         bool         `json:"-"`
 	Versions     []string     `json:"versions"`
 	Dependencies []Dependency `json:"dependencies"`
+	Graph        []Edge       `json:"graph,omitempty"`
 }
 
 type Dependency struct {
 	Name         string       `json:"name"`
 	Hash         string       `json:"hash"`
 	Version      string       `json:"version"`
+	License      string       `json:"license,omitempty"`
 	Versions     []string     `json:"versions"`
 	Dependencies []Dependency `json:"dependencies,omitempty"`
+	Error        string       `json:"error,omitempty"`
 }
 
 type ListResp struct {
@@ -54,14 +76,24 @@ func NewModule(ctx context.Context, afs afero.Fs, goBinPath string) (*Module, er
 	if err := validGoBinary(goBinPath); err != nil {
 		return nil, err
 	}
+
+	env, err := goEnv(goBinPath, "GOPROXY", "GONOPROXY")
+	if err != nil {
+		return nil, err
+	}
+
 	return &Module{
 		ctx:          ctx,
 		fs:           afs,
 		goBinPath:    goBinPath,
+		proxy:        goproxy.New(env["GOPROXY"], env["GONOPROXY"]),
 		Dependencies: make([]Dependency, 0),
 	}, nil
 }
 
+// FetchModuleInfo resolves module, which may carry an @query suffix, and
+// collects its versions and dependencies. The upgrade and patch queries are
+// relative to the version m already holds, if any.
 func (m *Module) FetchModuleInfo(module string) error {
 	tmpDir, err := afero.TempDir(m.fs, "", "go-list")
 	if err != nil {
@@ -71,28 +103,29 @@ func (m *Module) FetchModuleInfo(module string) error {
 		_ = m.fs.RemoveAll(tmpDir)
 	}(m.fs, tmpDir)
 
-	module = m.normalizeModulePath(module)
+	module = normalizeModulePath(module)
 
 	ctx, cancel := context.WithTimeout(m.ctx, m.getTimeout())
 	defer cancel()
 
-	module, version := m.splitModuleVersion(module)
+	module, version := splitModuleVersion(module)
 	m.Name = module
 
 	// Get versions from upstream
-	lr, err := m.fetchModuleVersions(ctx, tmpDir, module)
+	lr, err := m.fetchModuleVersions(ctx, module)
 	if err != nil {
 		return err
 	}
 
-	if version == "latest" {
-		version = lr.Version
+	query := version
+	if version, err = m.resolveQuery(ctx, lr, query); err != nil {
+		return fmt.Errorf("%s: %w", module, err)
 	}
 
 	m.Versions = lr.Versions
-	m.Version = m.pickVersion(version, lr.Versions)
+	m.Version = version
+	m.Query = query
 	m.Time = time.Now()
-	m.Hash = m.hashModule(fmt.Sprintf("%s@%s", module, version))
 
 	// Setup dummy mod
 	if err := m.setupTempModule(ctx, tmpDir); err != nil {
@@ -105,25 +138,152 @@ func (m *Module) FetchModuleInfo(module string) error {
 	}
 
 	// Extract dependencies
-	m.Dependencies, err = m.extractDependencies(ctx, tmpDir, module)
-	return err
+	if m.Dependencies, err = m.extractDependencies(ctx, tmpDir, module); err != nil {
+		return err
+	}
+
+	// Extract the requirement graph between them
+	if m.Graph, err = m.extractGraph(ctx, tmpDir); err != nil {
+		return err
+	}
+	m.linkDependencies()
+
+	// Classify the licenses of the modules go get downloaded
+	return m.extractLicenses(ctx, tmpDir)
+}
+
+// FetchVersions resolves the versions published upstream for module. The
+// returned Version is what the latest query resolves to.
+func (m *Module) FetchVersions(module string) (*ListResp, error) {
+	ctx, cancel := context.WithTimeout(m.ctx, m.getTimeout())
+	defer cancel()
+
+	return m.fetchModuleVersions(ctx, module)
 }
 
-func (m *Module) InstallModule(ctx context.Context) error {
-	cmd := exec.CommandContext(ctx, m.goBinPath, "install", fmt.Sprintf("%s@%s", m.Name, m.Version))
+// DownloadSum downloads path at version into the module cache and returns its
+// h1: hash, which the go command checks against the checksum database.
+func (m *Module) DownloadSum(path, version string) (string, error) {
+	ctx, cancel := context.WithTimeout(m.ctx, downloadTimeout)
+	defer cancel()
+
+	out, err := m.runGo(ctx, "mod", "download", "-json", fmt.Sprintf("%s@%s", path, version))
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s@%s: %w", path, version, err)
+	}
+
+	var resp struct {
+		Sum string `json:"Sum"`
+	}
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return "", fmt.Errorf("decoding download response failed: %w", err)
+	}
+	if resp.Sum == "" {
+		return "", fmt.Errorf("no checksum for %s@%s", path, version)
+	}
+	return resp.Sum, nil
+}
+
+// InstallModule runs go install for the module with its build tags into a
+// scratch directory of the store, keeps the binary in the store under its
+// version and checksum and points the GOBIN entry, named after the alias if
+// any, at it or at the shim. Earlier builds stay in the store for rollback.
+// When check is set it runs first against the fresh binary; if it fails,
+// nothing is stored and the GOBIN entry is left as it was.
+func (m *Module) InstallModule(ctx context.Context, check func(path string) error) error {
+	args := []string{"install"}
+	if len(m.Tags) > 0 {
+		args = append(args, "-tags", strings.Join(m.Tags, ","))
+	}
+	args = append(args, fmt.Sprintf("%s@%s", m.Name, m.Version))
+
+	dir, err := m.storeDir()
+	if err != nil {
+		return fmt.Errorf("invalid store path: %w", err)
+	}
+	if err := m.fs.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	tmpDir, err := afero.TempDir(m.fs, dir, ".goinstall-")
+	if err != nil {
+		return fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer func(fs afero.Fs, path string) {
+		_ = fs.RemoveAll(path)
+	}(m.fs, tmpDir)
+
+	cmd := exec.CommandContext(ctx, m.goBinPath, args...)
+	cmd.Env = append(os.Environ(), fmt.Sprintf("GOBIN=%s", tmpDir))
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("go install failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
 
-	gopath := os.Getenv("GOPATH")
-	if gopath == "" {
-		gopath = filepath.Join(os.Getenv("HOME"), "go")
+	built := filepath.Join(tmpDir, binaryName(m.Name))
+	if check != nil {
+		if err := check(built); err != nil {
+			return err
+		}
+	}
+	if m.Hash, m.Size, err = HashFile(m.fs, built); err != nil {
+		return fmt.Errorf("failed to record installed binary: %w", err)
 	}
-	cmd.Env = append(os.Environ(), fmt.Sprintf("GOBIN=%s/bin", gopath))
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("go install failed: %w", err)
+	stored, err := m.StorePath()
+	if err != nil {
+		return fmt.Errorf("invalid store path: %w", err)
+	}
+	if err := m.fs.MkdirAll(filepath.Dir(stored), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(stored), err)
+	}
+	// An identical build stored before is kept as it is.
+	if ok, _ := afero.Exists(m.fs, stored); !ok {
+		if err := m.fs.Rename(built, stored); err != nil {
+			return fmt.Errorf("failed to store binary: %w", err)
+		}
+	}
+	if err := m.Relink(); err != nil {
+		return err
+	}
+
+	if info, err := buildinfo.ReadFile(stored); err == nil {
+		m.GoVersion = info.GoVersion
 	}
 	return nil
 }
 
+// UninstallModule deletes the binary installed for the module from GOBIN,
+// along with every version of it in the store. A binary that is already gone
+// is not an error.
+func (m *Module) UninstallModule() error {
+	if err := m.fs.Remove(m.BinaryPath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove binary: %w", err)
+	}
+
+	dir, err := m.storeDir()
+	if err != nil {
+		return err
+	}
+	if err := m.fs.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to remove stored binaries: %w", err)
+	}
+	return nil
+}
+
+// BinaryPath returns the location of the module binary: its alias, or the
+// name go install gives it, in GOBIN.
+func (m *Module) BinaryPath() string {
+	if m.Alias != "" {
+		name := m.Alias
+		if runtime.GOOS == "windows" && filepath.Ext(name) != ".exe" {
+			name += ".exe"
+		}
+		return filepath.Join(BinDir(), name)
+	}
+	return filepath.Join(BinDir(), binaryName(m.Name))
+}
+
 func (m *Module) ToJSON() ([]byte, error) {
 	return json.MarshalIndent(m, "", "  ")
 }
@@ -161,33 +321,63 @@ func (m *Module) Report(db *database.Database) error {
 		return fmt.Errorf("failed to marshal dependencies: %w", err)
 	}
 
+	tagsJSON, err := json.Marshal(m.Tags)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tags: %w", err)
+	}
+
+	// The graph of each version is kept with its row, so a rollback can
+	// restore it.
+	graphJSON, err := json.Marshal(m.Graph)
+	if err != nil {
+		return fmt.Errorf("failed to marshal graph: %w", err)
+	}
+
 	query := `
-		INSERT INTO modules (name, version, versions, dependencies, hash, time)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO modules (name, version, versions, dependencies, hash, time, query, go_version, binary, size, tags, alias, license, graph)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(name, version) DO UPDATE
 		SET hash = excluded.hash,
 			time = excluded.time,
 			versions = excluded.versions,
-			dependencies = excluded.dependencies
+			dependencies = excluded.dependencies,
+			query = excluded.query,
+			go_version = excluded.go_version,
+			binary = excluded.binary,
+			size = excluded.size,
+			tags = excluded.tags,
+			alias = excluded.alias,
+			license = excluded.license,
+			graph = excluded.graph
 		`
-	if _, err := tx.Exec(query, m.Name, m.Version, versionsJSON, depsJSON, m.Hash, m.Time); err != nil {
+	if _, err := tx.Exec(query, m.Name, m.Version, versionsJSON, depsJSON, m.Hash, m.Time, m.Query, m.GoVersion, m.Binary, m.Size, tagsJSON, m.Alias, m.License, graphJSON); err != nil {
 		return fmt.Errorf("failed to insert module: %w", err)
 	}
 
 	depStmt := `
-		INSERT INTO dependencies (module_name, dep_name, dep_version, dep_hash)
-		VALUES (?, ?, ?, ?)
+		INSERT INTO dependencies (module_name, dep_name, dep_version, dep_hash, license)
+		VALUES (?, ?, ?, ?, ?)
 		ON CONFLICT(module_name, dep_name) DO UPDATE
 		SET dep_version = excluded.dep_version,
-			dep_hash = excluded.dep_hash
+			dep_hash = excluded.dep_hash,
+			license = excluded.license
 		`
 
+	// Dependencies of a previous install that are no longer required must not linger.
+	if _, err := tx.Exec(`DELETE FROM dependencies WHERE module_name = ?`, m.Name); err != nil {
+		return fmt.Errorf("failed to delete dependencies: %w", err)
+	}
+
 	for _, d := range m.Dependencies {
-		if _, err := tx.Exec(depStmt, m.Name, d.Name, d.Version, d.Hash); err != nil {
+		if _, err := tx.Exec(depStmt, m.Name, d.Name, d.Version, d.Hash, d.License); err != nil {
 			return fmt.Errorf("failed to insert dependency: %w", err)
 		}
 	}
 
+	if err := m.reportGraph(tx); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
@@ -208,25 +398,22 @@ func LoadModuleFromFile(fs afero.Fs, path string) (*Module, error) {
 }
 
 func (m *Module) dependency(module string) (*Dependency, error) {
-	tmpDir, err := afero.TempDir(m.fs, "", "go-list")
-	if err != nil {
-		return nil, fmt.Errorf("failed to create temp dir: %w", err)
-	}
-	defer func(fs afero.Fs, path string) {
-		_ = m.fs.RemoveAll(tmpDir)
-	}(m.fs, tmpDir)
-
 	ctx, cancel := context.WithTimeout(m.ctx, m.getTimeout())
 	defer cancel()
 
-	name, suffix := m.splitModuleVersion(module)
+	name, suffix := splitModuleVersion(module)
 
-	lr, err := m.fetchModuleVersions(ctx, tmpDir, name)
+	lr, err := m.fetchModuleVersions(ctx, name)
 	if err != nil {
 		return nil, err
 	}
 
-	version := m.pickVersion(suffix, lr.Versions)
+	// The suffix is the version selected by go list -m all, which may be a
+	// pseudo-version that is not part of the published list.
+	version := suffix
+	if version == "latest" {
+		version = lr.Version
+	}
 	return &Dependency{
 		Name:     name,
 		Hash:     m.hashModule(fmt.Sprintf("%s@%s", name, version)),
@@ -235,30 +422,21 @@ func (m *Module) dependency(module string) (*Dependency, error) {
 	}, nil
 }
 
-func (m *Module) fetchModuleVersions(ctx context.Context, dir, module string) (*ListResp, error) {
+func (m *Module) fetchModuleVersions(ctx context.Context, module string) (*ListResp, error) {
 	original := module
 	attempts := 0
 	const maxAttempts = 5
 
 	for {
-		cmd := exec.CommandContext(ctx, m.goBinPath, "list", "-m", "-versions", "-json", fmt.Sprintf("%s@latest", module))
-		cmd.Dir = dir
-
-		var lr ListResp
-		var out bytes.Buffer
-		cmd.Stdout = &out
-
-		if err := cmd.Run(); err == nil {
-			if err := json.NewDecoder(&out).Decode(&lr); err != nil {
-				return nil, fmt.Errorf("decoding list response failed: %w", err)
-			}
-
-			if len(lr.Versions) > 0 {
-				sort.Slice(lr.Versions, func(i, j int) bool {
-					return semver.Compare(lr.Versions[i], lr.Versions[j]) > 0
-				})
-				return &lr, nil
-			}
+		lr, err := m.listVersions(ctx, module)
+		if err == nil && (len(lr.Versions) > 0 || lr.Version != "") {
+			sort.Slice(lr.Versions, func(i, j int) bool {
+				return semver.Compare(lr.Versions[i], lr.Versions[j]) > 0
+			})
+			return lr, nil
+		}
+		if errors.Is(err, goproxy.ErrDisabled) {
+			return nil, err
 		}
 
 		// Step back one path segment
@@ -273,6 +451,71 @@ func (m *Module) fetchModuleVersions(ctx context.Context, dir, module string) (*
 	return nil, fmt.Errorf("failed to resolve module versions for %q (initially %q)", module, original)
 }
 
+// listVersions asks the module proxies for the versions of module, or for
+// its latest pseudo-version when it has no tags, and falls back to go list
+// when GOPROXY or GONOPROXY require a direct lookup.
+func (m *Module) listVersions(ctx context.Context, module string) (*ListResp, error) {
+	versions, err := m.proxy.List(ctx, module)
+	if errors.Is(err, goproxy.ErrDirect) {
+		return m.goListVersions(ctx, module)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	lr := &ListResp{
+		Path:     module,
+		Version:  newestRelease(semverSorted(versions), func(string) bool { return true }),
+		Versions: versions,
+	}
+	if len(versions) == 0 {
+		// A module without tags is at the pseudo-version of its newest commit.
+		info, err := m.proxy.Latest(ctx, module)
+		if err != nil {
+			return nil, err
+		}
+		lr.Version = info.Version
+	}
+	return lr, nil
+}
+
+func (m *Module) goListVersions(ctx context.Context, module string) (*ListResp, error) {
+	out, err := m.runGo(ctx, "list", "-m", "-versions", "-json", fmt.Sprintf("%s@latest", module))
+	if err != nil {
+		return nil, err
+	}
+
+	var lr ListResp
+	if err := json.Unmarshal(out, &lr); err != nil {
+		return nil, fmt.Errorf("decoding list response failed: %w", err)
+	}
+	return &lr, nil
+}
+
+// runGo runs the go command in a scratch directory, outside of any module,
+// and returns its standard output.
+func (m *Module) runGo(ctx context.Context, args ...string) ([]byte, error) {
+	tmpDir, err := afero.TempDir(m.fs, "", "go-list")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer func(fs afero.Fs, path string) {
+		_ = m.fs.RemoveAll(tmpDir)
+	}(m.fs, tmpDir)
+
+	cmd := exec.CommandContext(ctx, m.goBinPath, args...)
+	cmd.Dir = tmpDir
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go %s failed: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
 func (m *Module) setupTempModule(ctx context.Context, dir string) error {
 	cmd := exec.CommandContext(ctx, m.goBinPath, "mod", "init", dummyModuleName)
 	cmd.Dir = dir
@@ -285,6 +528,9 @@ func (m *Module) getModule(ctx context.Context, dir, moduleWithVersion string) e
 	return cmd.Run()
 }
 
+// extractDependencies resolves every module in the build list of dir with at
+// most getConcurrency lookups in flight. The result keeps the go list order;
+// a dependency whose lookup failed is kept with its error recorded.
 func (m *Module) extractDependencies(ctx context.Context, dir, self string) ([]Dependency, error) {
 	cmd := exec.CommandContext(ctx, m.goBinPath, "list", "-m", "all")
 	cmd.Dir = dir
@@ -295,7 +541,7 @@ func (m *Module) extractDependencies(ctx context.Context, dir, self string) ([]D
 	}
 
 	seen := make(map[string]struct{}) // module name deduplication
-	var deps []Dependency
+	var names, versions []string
 	lines := strings.Split(string(out), "\n")
 	for _, line := range lines {
 		fields := strings.Fields(line)
@@ -313,14 +559,56 @@ func (m *Module) extractDependencies(ctx context.Context, dir, self string) ([]D
 		}
 		seen[name] = struct{}{}
 
-		dep, err := m.dependency(name)
-		if err == nil {
-			deps = append(deps, *dep)
+		version := ""
+		if len(fields) > 1 {
+			version = fields[1]
 		}
+		names = append(names, name)
+		versions = append(versions, version)
 	}
+
+	deps := make([]Dependency, len(names))
+	sem := make(chan struct{}, m.getConcurrency())
+
+	var wg sync.WaitGroup
+	for i := range names {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			query := names[i]
+			if versions[i] != "" {
+				query = fmt.Sprintf("%s@%s", names[i], versions[i])
+			}
+
+			dep, err := m.dependency(query)
+			if err != nil {
+				deps[i] = Dependency{Name: names[i], Version: versions[i], Error: err.Error()}
+				return
+			}
+			deps[i] = *dep
+		}(i)
+	}
+	wg.Wait()
+
 	return deps, nil
 }
 
+// SetConcurrency sets how many dependencies are resolved at once.
+func (m *Module) SetConcurrency(n int) {
+	m.concurrency = n
+}
+
+func (m *Module) getConcurrency() int {
+	if m.concurrency < 1 {
+		return 8
+	}
+	return m.concurrency
+}
+
 func (m *Module) getTimeout() time.Duration {
 	if m.timeout == 0 {
 		return 10 * time.Second
@@ -328,7 +616,7 @@ func (m *Module) getTimeout() time.Duration {
 	return m.timeout
 }
 
-func (m *Module) splitModuleVersion(full string) (string, string) {
+func splitModuleVersion(full string) (string, string) {
 	parts := strings.SplitN(full, "@", 2)
 	if len(parts) == 2 {
 		return parts[0], parts[1]
@@ -336,21 +624,50 @@ func (m *Module) splitModuleVersion(full string) (string, string) {
 	return full, "latest"
 }
 
+// SortedTags returns a sorted copy of tags, so build tags recorded in another
+// order compare equal.
+func SortedTags(tags []string) []string {
+	sorted := slices.Clone(tags)
+	slices.Sort(sorted)
+	return sorted
+}
+
 func (m *Module) hashModule(input string) string {
 	return fmt.Sprintf("%x", sha256.Sum256([]byte(input)))
 }
 
-func (m *Module) pickVersion(preferred string, versions []string) string {
-	if len(versions) > 0 {
-		return versions[0]
+// pickVersion returns the published version matching preferred exactly. A
+// missing "v" prefix is tolerated. Unknown versions are rejected with the
+// closest published versions in the error.
+func (m *Module) pickVersion(preferred string, versions []string) (string, error) {
+	if preferred == "" {
+		return "", errors.New("empty version")
+	}
+
+	candidate := preferred
+	if !strings.HasPrefix(candidate, "v") {
+		candidate = "v" + candidate
 	}
-	if preferred != "" {
-		return preferred
+	if slices.Contains(versions, candidate) {
+		return candidate, nil
 	}
-	return ""
+
+	if len(versions) == 0 {
+		return "", fmt.Errorf("version %s not found, no versions are published", preferred)
+	}
+	return "", fmt.Errorf("version %s not found, nearby versions: %s", preferred, strings.Join(nearbyVersions(candidate, versions, 3), ", "))
+}
+
+// nearbyVersions returns up to n versions on each side of version in the
+// newest-first list versions.
+func nearbyVersions(version string, versions []string, n int) []string {
+	pos := sort.Search(len(versions), func(i int) bool {
+		return semver.Compare(versions[i], version) < 0
+	})
+	return versions[max(pos-n, 0):min(pos+n, len(versions))]
 }
 
-func (m *Module) normalizeModulePath(input string) string {
+func normalizeModulePath(input string) string {
 	// Strip known prefixes
 	prefixes := []string{
 		"https://", "http://", "git://", "ssh://", "git@", "ssh@", "www.",
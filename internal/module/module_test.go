@@ -2,8 +2,19 @@ package module
 
 import (
 	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"github.com/inovacc/goinstall/internal/database"
+	"github.com/inovacc/goinstall/internal/goproxy"
 	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestModule_Check(t *testing.T) {
@@ -16,7 +27,7 @@ func TestModule_Check(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if err := mod.SaveToFile("module_data.json"); err != nil {
+	if err := mod.SaveToFile(filepath.Join(t.TempDir(), "module_data.json")); err != nil {
 		t.Fatal(err)
 	}
 }
@@ -31,11 +42,12 @@ func TestModule_Check_Latest(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if err := mod.SaveToFile("module_data_latest.json"); err != nil {
+	path := filepath.Join(t.TempDir(), "module_data_latest.json")
+	if err := mod.SaveToFile(path); err != nil {
 		t.Fatal(err)
 	}
 
-	mod1, err := LoadModuleFromFile(afero.NewOsFs(), "module_data_latest.json")
+	mod1, err := LoadModuleFromFile(afero.NewOsFs(), path)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -44,3 +56,424 @@ func TestModule_Check_Latest(t *testing.T) {
 		t.Fatalf("expected %s but got %s", mod.Name, mod1.Name)
 	}
 }
+
+func TestLoadModule(t *testing.T) {
+	afs := afero.NewOsFs()
+	viper.Set("installPath", filepath.Join(t.TempDir(), "modules.db"))
+
+	db, err := database.NewDatabase(context.TODO(), afs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(db *database.Database) {
+		_ = db.Close()
+	}(db)
+
+	mod := &Module{
+		Name:      "github.com/inovacc/ksuid/cmd/ksuid",
+		Version:   "v0.1.0",
+		Versions:  []string{"v0.2.0", "v0.1.0"},
+		GoVersion: "go1.24.2",
+		Time:      time.Now(),
+		Dependencies: []Dependency{
+			{Name: "github.com/inovacc/ksuid", Version: "v0.1.0"},
+			{Name: "github.com/spf13/cobra", Version: "v1.9.1"},
+		},
+		Graph: []Edge{
+			{From: "github.com/inovacc/ksuid/cmd/ksuid", FromVersion: "v0.1.0", To: "github.com/inovacc/ksuid", Version: "v0.1.0"},
+			{From: "github.com/inovacc/ksuid", FromVersion: "v0.1.0", To: "github.com/spf13/cobra", Version: "v1.8.0"},
+		},
+	}
+	mod.linkDependencies()
+	if err := mod.Report(db); err != nil {
+		t.Fatal(err)
+	}
+
+	mods, err := LoadModules(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mods) != 1 {
+		t.Fatalf("expected 1 module but got %d", len(mods))
+	}
+
+	loaded, err := LoadModule(db, ParseName("https://github.com/inovacc/ksuid/cmd/ksuid@v0.1.0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Latest() != "v0.2.0" {
+		t.Fatalf("expected latest v0.2.0 but got %s", loaded.Latest())
+	}
+	if len(loaded.Dependencies) != 2 || loaded.Dependencies[1].Name != "github.com/spf13/cobra" {
+		t.Fatalf("unexpected dependencies: %+v", loaded.Dependencies)
+	}
+	if deps := loaded.Dependencies[0].Dependencies; len(deps) != 1 || deps[0].Version != "v1.8.0" {
+		t.Fatalf("expected ksuid to require cobra v1.8.0, got %+v", deps)
+	}
+	if len(loaded.Graph) != 2 {
+		t.Fatalf("expected 2 graph edges but got %+v", loaded.Graph)
+	}
+	if loaded.Time.IsZero() {
+		t.Fatal("expected install time to be loaded")
+	}
+	if loaded.GoVersion != "go1.24.2" {
+		t.Fatalf("expected go version go1.24.2 but got %q", loaded.GoVersion)
+	}
+
+	if _, err := LoadModule(db, "example.com/missing"); !errors.Is(err, ErrNotTracked) {
+		t.Fatalf("expected ErrNotTracked but got %v", err)
+	}
+	if err := loaded.Purge(db); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadModule(db, mod.Name); !errors.Is(err, ErrNotTracked) {
+		t.Fatalf("expected purged module to be untracked but got %v", err)
+	}
+}
+
+func TestModule_LoadBinary(t *testing.T) {
+	m := &Module{fs: afero.NewOsFs()}
+
+	// The test binary is built from the checkout, not a module version.
+	if err := m.LoadBinary(os.Args[0]); !errors.Is(err, ErrNoVersion) {
+		t.Fatalf("expected ErrNoVersion but got %v", err)
+	}
+
+	script := filepath.Join(t.TempDir(), "tool.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.LoadBinary(script); err == nil || errors.Is(err, ErrNoVersion) {
+		t.Fatalf("expected a build info error but got %v", err)
+	}
+}
+
+func TestModule_Activate(t *testing.T) {
+	afs := afero.NewOsFs()
+	dir := t.TempDir()
+	viper.Set("installPath", filepath.Join(dir, "modules.db"))
+	t.Setenv("GOBIN", filepath.Join(dir, "bin"))
+
+	db, err := database.NewDatabase(context.TODO(), afs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(db *database.Database) {
+		_ = db.Close()
+	}(db)
+
+	m := &Module{fs: afs, Name: "example.com/tool/v2", Version: "v2.1.0", Hash: fmt.Sprintf("%x", sha256.Sum256([]byte("v2.1.0")))}
+	stored, err := m.StorePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "store", "example.com", "tool", "v2", "@v", "v2.1.0", m.Hash, binaryName(m.Name)); stored != want {
+		t.Fatalf("expected store path %s but got %s", want, stored)
+	}
+	rebuilt := &Module{Name: m.Name, Version: m.Version, Hash: fmt.Sprintf("%x", sha256.Sum256([]byte("v2.1.0 -tags netgo")))}
+	if path, err := rebuilt.StorePath(); err != nil || path == stored {
+		t.Fatalf("expected a rebuild with other content to get its own entry, got %s, %v", path, err)
+	}
+	if err := afs.MkdirAll(filepath.Dir(stored), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := afero.WriteFile(afs, stored, []byte("tampered"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Activate(db); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Fatalf("expected a checksum error but got %v", err)
+	}
+
+	if err := afero.WriteFile(afs, stored, []byte("v2.1.0"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Activate(db); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(m.BinaryPath()); err != nil || string(data) != "v2.1.0" {
+		t.Fatalf("expected GOBIN entry to reach the stored binary, got %q, %v", data, err)
+	}
+	if !m.Stored(afs) {
+		t.Fatal("expected the version to be stored")
+	}
+
+	if err := m.SetShim(db, true); err != nil {
+		t.Fatal(err)
+	}
+	if enabled, err := ShimEnabled(db, m.Name); err != nil || !enabled {
+		t.Fatalf("expected the shim to be enabled, got %v, %v", enabled, err)
+	}
+	if err := m.Relink(); err != nil {
+		t.Fatal(err)
+	}
+	if m.Binary != stored {
+		t.Fatalf("expected a shimmed binary to be recorded in the store, got %s", m.Binary)
+	}
+
+	if err := m.UninstallModule(); err != nil {
+		t.Fatal(err)
+	}
+	if m.Stored(afs) {
+		t.Fatal("expected uninstall to remove the stored versions")
+	}
+}
+
+func TestModule_link_Copy(t *testing.T) {
+	afs := afero.NewMemMapFs()
+	t.Setenv("GOBIN", "/bin")
+	if err := afero.WriteFile(afs, "/store/tool", []byte("tool"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// MemMapFs has no symlinks, the binary is copied instead.
+	m := &Module{fs: afs, Name: "example.com/tool"}
+	if err := m.link("/store/tool"); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := afero.ReadFile(afs, m.BinaryPath()); err != nil || string(data) != "tool" {
+		t.Fatalf("expected a copy of the stored binary, got %q, %v", data, err)
+	}
+}
+
+func TestBinaryName(t *testing.T) {
+	tests := map[string]string{
+		"github.com/inovacc/ksuid/cmd/ksuid":                     "ksuid",
+		"github.com/golangci/golangci-lint/v2/cmd/golangci-lint": "golangci-lint",
+		"github.com/example/tool/v2":                             "tool",
+		"tool":                                                   "tool",
+	}
+	for pkg, want := range tests {
+		if runtime.GOOS == "windows" {
+			want += ".exe"
+		}
+		if got := binaryName(pkg); got != want {
+			t.Errorf("binaryName(%q) = %q, want %q", pkg, got, want)
+		}
+	}
+}
+
+func TestModule_pickVersion(t *testing.T) {
+	var m Module
+	versions := []string{"v1.3.0", "v1.2.1", "v1.2.0", "v1.1.0", "v1.0.0"}
+
+	if v, err := m.pickVersion("v1.2.0", versions); err != nil || v != "v1.2.0" {
+		t.Fatalf("expected v1.2.0 but got %q, %v", v, err)
+	}
+
+	if v, err := m.pickVersion("1.1.0", versions); err != nil || v != "v1.1.0" {
+		t.Fatalf("expected v1.1.0 but got %q, %v", v, err)
+	}
+
+	_, err := m.pickVersion("v1.2.5", versions)
+	if err == nil {
+		t.Fatal("expected an error for an unpublished version")
+	}
+	if !strings.Contains(err.Error(), "v1.3.0, v1.2.1, v1.2.0, v1.1.0") {
+		t.Fatalf("expected nearby versions in error, got %v", err)
+	}
+}
+
+func TestModule_matchQuery(t *testing.T) {
+	var m Module
+	versions := []string{"v2.1.0", "v2.0.0", "v1.5.0-rc.1", "v1.4.3", "v1.4.2", "v1.4.0", "v1.3.9", "v0.9.1", "v0.9.0"}
+
+	tests := []struct {
+		query   string
+		current string
+		want    string
+	}{
+		{"^1.4", "", "v1.4.3"},
+		{"^0.9", "", "v0.9.1"},
+		{"~1.4.2", "", "v1.4.3"},
+		{"~1", "", "v1.4.3"},
+		{"<2", "", "v1.4.3"},
+		{"<=v1.4.2", "", "v1.4.2"},
+		{">=1.4", "", "v2.1.0"},
+		{"v1", "", "v1.4.3"},
+		{"v1.3", "", "v1.3.9"},
+		{"1.4.0", "", "v1.4.0"},
+		{"upgrade", "v1.4.0", "v2.1.0"},
+		{"upgrade", "v3.0.0", "v3.0.0"},
+		{"patch", "v1.4.0", "v1.4.3"},
+		{"patch", "", "v2.1.0"},
+	}
+
+	for _, tt := range tests {
+		got, ok, err := m.matchQuery(tt.query, tt.current, versions)
+		if !ok || err != nil || got != tt.want {
+			t.Errorf("matchQuery(%q, %q) = %q, %v, %v, want %q", tt.query, tt.current, got, ok, err, tt.want)
+		}
+	}
+
+	for _, query := range []string{"master", "a1b2c3d"} {
+		if _, ok, _ := m.matchQuery(query, "", versions); ok {
+			t.Errorf("expected %q to be left to go list", query)
+		}
+	}
+
+	if _, ok, err := m.matchQuery("^3", "", versions); !ok || err == nil {
+		t.Error("expected an error when no version matches")
+	}
+}
+
+func TestSatisfies(t *testing.T) {
+	tests := []struct {
+		query, version string
+		want           bool
+	}{
+		{"v1.2.3", "v1.2.3", true},
+		{"1.2.3", "v1.2.3", true},
+		{"v1.2.3", "v1.2.4", false},
+		{"^1.2", "v1.9.0", true},
+		{"^1.2", "v2.0.0", false},
+		{"~1.2.0", "v1.2.9", true},
+		{"v1", "v1.4.0", true},
+		{"<2", "v2.0.0", false},
+		{"latest", "v1.0.0", false},
+		{"master", "v1.0.0", false},
+	}
+
+	for _, tt := range tests {
+		if got := Satisfies(tt.query, tt.version); got != tt.want {
+			t.Fatalf("Satisfies(%q, %q) = %v, want %v", tt.query, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestIsConstraint(t *testing.T) {
+	for query, want := range map[string]bool{
+		"":        false,
+		"latest":  false,
+		"v1.2.3":  false,
+		"a1b2c3d": false,
+		"^1.4":    true,
+		"<2":      true,
+		"v1":      true,
+		"patch":   true,
+		"main":    true,
+	} {
+		if got := IsConstraint(query); got != want {
+			t.Errorf("IsConstraint(%q) = %v, want %v", query, got, want)
+		}
+	}
+}
+
+func TestModule_resolveQuery_unlisted(t *testing.T) {
+	const (
+		path   = "example.com/tool"
+		pseudo = "v0.0.0-20240101000000-abcdefabcdef"
+	)
+
+	dir := t.TempDir()
+	info := filepath.Join(dir, "example.com", "tool", "@v", pseudo+".info")
+	if err := os.MkdirAll(filepath.Dir(info), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(info, []byte(`{"Version":"`+pseudo+`","Time":"2024-01-01T00:00:00Z"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := Module{proxy: goproxy.New("file://"+filepath.ToSlash(dir), "")}
+	lr := &ListResp{Path: path, Version: "v1.0.0", Versions: []string{"v1.0.0"}}
+
+	if v, err := m.resolveQuery(context.TODO(), lr, pseudo); err != nil || v != pseudo {
+		t.Fatalf("expected %s but got %q, %v", pseudo, v, err)
+	}
+
+	_, err := m.resolveQuery(context.TODO(), lr, "v1.0.1")
+	if err == nil || !strings.Contains(err.Error(), "nearby versions: v1.0.0") {
+		t.Fatalf("expected nearby versions for an unpublished version, got %v", err)
+	}
+}
+
+func TestModule_fetchModuleVersions_untagged(t *testing.T) {
+	const pseudo = "v0.0.0-20240101000000-abcdefabcdef"
+
+	dir := t.TempDir()
+	root := filepath.Join(dir, "example.com", "tool")
+	if err := os.MkdirAll(filepath.Join(root, "@v"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "@v", "list"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "@latest"), []byte(`{"Version":"`+pseudo+`"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := Module{proxy: goproxy.New("file://"+filepath.ToSlash(dir), "")}
+	lr, err := m.fetchModuleVersions(context.TODO(), "example.com/tool/cmd/tool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lr.Path != "example.com/tool" || lr.Version != pseudo || len(lr.Versions) != 0 {
+		t.Fatalf("expected example.com/tool at %s without versions, got %+v", pseudo, lr)
+	}
+}
+
+func TestModule_extractDependencies(t *testing.T) {
+	proxy := t.TempDir()
+	files := map[string]string{
+		"example.com/a/@v/list": "v1.0.0\nv1.1.0\n",
+		"example.com/b/@v/list": "v0.2.0\n",
+		"example.com/d/@v/list": "v2.0.0+incompatible\n",
+	}
+	// example.com/c has no version list, so looking it up fails.
+	for _, mv := range []string{"a@v1.0.0", "b@v0.2.0", "c@v0.1.0", "d@v2.0.0+incompatible"} {
+		name, version, _ := strings.Cut(mv, "@")
+		files["example.com/"+name+"/@v/"+version+".mod"] = "module example.com/" + name + "\n"
+		files["example.com/"+name+"/@v/"+version+".info"] = `{"Version":"` + version + `"}`
+	}
+	for name, data := range files {
+		path := filepath.Join(proxy, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Setenv("GOPROXY", "file://"+filepath.ToSlash(proxy))
+	t.Setenv("GONOPROXY", "")
+	t.Setenv("GOPRIVATE", "")
+	t.Setenv("GOSUMDB", "off")
+	t.Setenv("GOFLAGS", "-mod=mod -modcacherw")
+	t.Setenv("GOMODCACHE", t.TempDir())
+
+	dir := t.TempDir()
+	gomod := "module dummy\n\ngo 1.21\n\nrequire (\n\texample.com/a v1.0.0\n\texample.com/b v0.2.0\n\texample.com/c v0.1.0\n\texample.com/d v2.0.0+incompatible\n)\n"
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(gomod), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := NewModule(context.TODO(), afero.NewOsFs(), "go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.SetConcurrency(3)
+
+	deps, err := m.extractDependencies(context.TODO(), dir, "example.com/tool")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, d := range deps {
+		got = append(got, d.Name+"@"+d.Version)
+	}
+	want := []string{"example.com/a@v1.0.0", "example.com/b@v0.2.0", "example.com/c@v0.1.0", "example.com/d@v2.0.0+incompatible"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("expected dependencies %v in go list order, got %v", want, got)
+	}
+
+	for _, d := range deps {
+		if failed := d.Name == "example.com/c"; failed != (d.Error != "") {
+			t.Errorf("unexpected error of %s: %q", d.Name, d.Error)
+		}
+	}
+	if len(deps[0].Versions) != 2 || deps[0].Versions[0] != "v1.1.0" {
+		t.Errorf("expected the published versions of example.com/a newest first, got %v", deps[0].Versions)
+	}
+}
@@ -0,0 +1,326 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/inovacc/goinstall/internal/goproxy"
+	"golang.org/x/mod/semver"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	queryLatest  = "latest"
+	queryUpgrade = "upgrade"
+	queryPatch   = "patch"
+)
+
+var commitHash = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
+// IsConstraint reports whether query keeps restricting the versions an
+// update may install. Exact versions, commits and latest do not: updating a
+// module installed with them moves it to the newest release.
+func IsConstraint(query string) bool {
+	switch {
+	case query == "" || query == queryLatest:
+		return false
+	case commitHash.MatchString(query):
+		return false
+	case isFullVersion(query):
+		return false
+	default:
+		return true
+	}
+}
+
+// Satisfies reports whether version meets query without looking anything up:
+// it is the exact version asked for or falls in the range. Queries that
+// depend on what is published, such as latest or a branch, are never met.
+func Satisfies(query, version string) bool {
+	if isFullVersion(query) {
+		if !strings.HasPrefix(query, "v") {
+			query = "v" + query
+		}
+		return semver.Compare(query, version) == 0
+	}
+
+	match, ok, err := parseConstraint(query)
+	return ok && err == nil && match(version)
+}
+
+// UpdateQuery returns the query an update of m resolves: the stored query
+// when it is a constraint, latest otherwise.
+func (m *Module) UpdateQuery() string {
+	if IsConstraint(m.Query) {
+		return m.Query
+	}
+	return queryLatest
+}
+
+// KnownUpdate returns the version an update of m would install according to
+// the versions already recorded, without looking anything up upstream.
+func (m *Module) KnownUpdate() (string, error) {
+	query := m.UpdateQuery()
+	if query == queryLatest {
+		return m.Latest(), nil
+	}
+
+	version, ok, err := m.matchQuery(query, m.Version, m.Versions)
+	if !ok {
+		return "", fmt.Errorf("cannot resolve %s@%s from the recorded versions", m.Name, query)
+	}
+	return version, err
+}
+
+// ResolveVersion resolves query for the module against the versions published
+// upstream. The upgrade and patch queries are relative to the version m holds.
+func (m *Module) ResolveVersion(query string) (string, error) {
+	ctx, cancel := context.WithTimeout(m.ctx, m.getTimeout())
+	defer cancel()
+
+	lr, err := m.fetchModuleVersions(ctx, m.Name)
+	if err != nil {
+		return "", err
+	}
+	return m.resolveQuery(ctx, lr, query)
+}
+
+// resolveQuery resolves query against the versions in lr. Queries that are
+// not about tagged versions, such as branches and commits, are resolved by
+// go list -m against the module root, and versions missing from lr, such as
+// pseudo-versions, through the .info of the module proxy.
+func (m *Module) resolveQuery(ctx context.Context, lr *ListResp, query string) (string, error) {
+	if query == "" || query == queryLatest {
+		return lr.Version, nil
+	}
+
+	version, ok, err := m.matchQuery(query, m.Version, lr.Versions)
+	if ok && (err == nil || !isFullVersion(query)) {
+		return version, err
+	}
+	if ok {
+		return m.resolveUnlisted(ctx, lr.Path, query, err)
+	}
+	return m.goListVersion(ctx, lr.Path, query)
+}
+
+// resolveUnlisted looks up a version that is not in the list of tagged
+// versions, such as the pseudo-version a branch or commit was installed at.
+// notFound is returned when the version does not exist upstream either.
+func (m *Module) resolveUnlisted(ctx context.Context, path, version string, notFound error) (string, error) {
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+
+	info, err := m.proxy.Info(ctx, path, version)
+	switch {
+	case err == nil:
+		return info.Version, nil
+	case errors.Is(err, goproxy.ErrDirect):
+		return m.goListVersion(ctx, path, version)
+	case errors.Is(err, goproxy.ErrNotFound):
+		return "", notFound
+	default:
+		return "", fmt.Errorf("failed to resolve %s@%s: %w", path, version, err)
+	}
+}
+
+// goListVersion resolves query for the module at path with go list -m.
+func (m *Module) goListVersion(ctx context.Context, path, query string) (string, error) {
+	out, err := m.runGo(ctx, "list", "-m", "-json", fmt.Sprintf("%s@%s", path, query))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s@%s: %w", path, query, err)
+	}
+
+	var resolved ListResp
+	if err := json.Unmarshal(out, &resolved); err != nil {
+		return "", fmt.Errorf("decoding list response failed: %w", err)
+	}
+	return resolved.Version, nil
+}
+
+// matchQuery resolves the queries that only depend on the published versions,
+// newest first. The boolean is false when query is not such a query.
+func (m *Module) matchQuery(query, current string, versions []string) (string, bool, error) {
+	switch {
+	case query == queryUpgrade:
+		latest := newestRelease(versions, func(string) bool { return true })
+		if semver.Compare(current, latest) > 0 {
+			return current, true, nil
+		}
+		return orNotFound(query, latest, versions)
+	case query == queryPatch:
+		if !semver.IsValid(current) {
+			return orNotFound(query, newestRelease(versions, func(string) bool { return true }), versions)
+		}
+		return orNotFound(query, newestRelease(versions, func(v string) bool {
+			return semver.MajorMinor(v) == semver.MajorMinor(current) && semver.Compare(v, current) >= 0
+		}), versions)
+	case commitHash.MatchString(query):
+		return "", false, nil
+	case isFullVersion(query):
+		v, err := m.pickVersion(query, versions)
+		return v, true, err
+	}
+
+	match, ok, err := parseConstraint(query)
+	if !ok || err != nil {
+		return "", ok, err
+	}
+	return orNotFound(query, newestRelease(versions, match), versions)
+}
+
+// parseConstraint turns a range query into a predicate. Supported are
+// prefixes (v1, v1.2), caret (^1.4), tilde (~1.4.2) and comparisons
+// (<2, <=v1.5, >1.2, >=1.2.3).
+func parseConstraint(query string) (func(string) bool, bool, error) {
+	for _, op := range []string{"<=", ">=", "<", ">", "^", "~"} {
+		rest, found := strings.CutPrefix(query, op)
+		if !found {
+			continue
+		}
+
+		bound, parts, err := parseBound(rest)
+		if err != nil {
+			return nil, true, fmt.Errorf("invalid version query %q: %w", query, err)
+		}
+
+		switch op {
+		case "<=":
+			return func(v string) bool { return semver.Compare(v, bound) <= 0 }, true, nil
+		case ">=":
+			return func(v string) bool { return semver.Compare(v, bound) >= 0 }, true, nil
+		case "<":
+			return func(v string) bool { return semver.Compare(v, bound) < 0 }, true, nil
+		case ">":
+			return func(v string) bool { return semver.Compare(v, bound) > 0 }, true, nil
+		case "^":
+			upper := caretUpper(bound, parts)
+			return func(v string) bool {
+				return semver.Compare(v, bound) >= 0 && semver.Compare(v, upper) < 0
+			}, true, nil
+		case "~":
+			upper := tildeUpper(bound, parts)
+			return func(v string) bool {
+				return semver.Compare(v, bound) >= 0 && semver.Compare(v, upper) < 0
+			}, true, nil
+		}
+	}
+
+	// A partial version such as v1 or v1.2 selects the newest matching release.
+	if bound, parts, err := parseBound(query); err == nil && parts < 3 {
+		prefix := semver.Major(bound)
+		if parts == 2 {
+			prefix = semver.MajorMinor(bound)
+		}
+		return func(v string) bool {
+			return v == prefix || strings.HasPrefix(v, prefix+".")
+		}, true, nil
+	}
+
+	return nil, false, nil
+}
+
+// parseBound returns the canonical form of a possibly partial version, with
+// or without "v" prefix, and the number of components that were given.
+func parseBound(s string) (string, int, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "v") {
+		s = "v" + s
+	}
+	if !semver.IsValid(s) {
+		return "", 0, fmt.Errorf("%q is not a semantic version", s)
+	}
+
+	core, _, _ := strings.Cut(strings.TrimPrefix(s, "v"), "-")
+	core, _, _ = strings.Cut(core, "+")
+	return semver.Canonical(s), len(strings.Split(core, ".")), nil
+}
+
+// caretUpper returns the exclusive upper bound of ^bound: the next version
+// that changes the leftmost non-zero component that was given.
+func caretUpper(bound string, parts int) string {
+	major, minor, patch := versionParts(bound)
+	switch {
+	case major > 0 || parts == 1:
+		return fmt.Sprintf("v%d.0.0", major+1)
+	case minor > 0 || parts == 2:
+		return fmt.Sprintf("v0.%d.0", minor+1)
+	default:
+		return fmt.Sprintf("v0.0.%d", patch+1)
+	}
+}
+
+// tildeUpper returns the exclusive upper bound of ~bound: the next minor
+// version, or the next major version when only the major was given.
+func tildeUpper(bound string, parts int) string {
+	major, minor, _ := versionParts(bound)
+	if parts == 1 {
+		return fmt.Sprintf("v%d.0.0", major+1)
+	}
+	return fmt.Sprintf("v%d.%d.0", major, minor+1)
+}
+
+func versionParts(v string) (int, int, int) {
+	core, _, _ := strings.Cut(strings.TrimPrefix(semver.Canonical(v), "v"), "-")
+	fields := strings.SplitN(core, ".", 3)
+	nums := make([]int, 3)
+	for i, f := range fields {
+		nums[i], _ = strconv.Atoi(f)
+	}
+	return nums[0], nums[1], nums[2]
+}
+
+// isFullVersion reports whether s names a single version such as v1.2.3,
+// with or without the "v" prefix.
+func isFullVersion(s string) bool {
+	if !strings.HasPrefix(s, "v") {
+		s = "v" + s
+	}
+	if !semver.IsValid(s) {
+		return false
+	}
+	_, parts, err := parseBound(s)
+	return err == nil && parts == 3
+}
+
+// semverSorted returns a copy of versions sorted from newest to oldest.
+func semverSorted(versions []string) []string {
+	sorted := slices.Clone(versions)
+	slices.SortFunc(sorted, func(a, b string) int {
+		return semver.Compare(b, a)
+	})
+	return sorted
+}
+
+// newestRelease returns the newest version matching match, preferring
+// releases over pre-releases.
+func newestRelease(versions []string, match func(string) bool) string {
+	prerelease := ""
+	for _, v := range versions {
+		if !semver.IsValid(v) || !match(v) {
+			continue
+		}
+		if semver.Prerelease(v) == "" {
+			return v
+		}
+		if prerelease == "" {
+			prerelease = v
+		}
+	}
+	return prerelease
+}
+
+func orNotFound(query, version string, versions []string) (string, bool, error) {
+	if version != "" {
+		return version, true, nil
+	}
+	if len(versions) == 0 {
+		return "", true, fmt.Errorf("no version matches %q, no versions are published", query)
+	}
+	return "", true, fmt.Errorf("no version matches %q, newest versions: %s", query, strings.Join(versions[:min(len(versions), 5)], ", "))
+}
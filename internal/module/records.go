@@ -0,0 +1,326 @@
+package module
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/inovacc/goinstall/internal/database"
+	"golang.org/x/mod/semver"
+	"log"
+	"sort"
+	"time"
+)
+
+// ErrNotTracked is returned when a module has no record in the database.
+var ErrNotTracked = errors.New("module is not tracked")
+
+const selectModules = `SELECT name, version, versions, dependencies, hash, time, query, go_version, binary, size, tags, alias, license FROM modules`
+
+// LoadModules returns every module row stored in db ordered by name and install time.
+func LoadModules(db *database.Database) ([]Module, error) {
+	rows, err := db.Query(selectModules + ` ORDER BY name, time`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query modules: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	var mods []Module
+	for rows.Next() {
+		mod, err := scanModule(rows)
+		if err != nil {
+			return nil, err
+		}
+		mods = append(mods, *mod)
+	}
+	return mods, rows.Err()
+}
+
+// LoadInstalled returns the most recently installed record of every tracked module.
+func LoadInstalled(db *database.Database) ([]Module, error) {
+	mods, err := LoadModules(db)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rows are ordered by name and time, so the last row of each name is current.
+	installed := make([]Module, 0, len(mods))
+	for _, m := range mods {
+		if n := len(installed); n > 0 && installed[n-1].Name == m.Name {
+			installed[n-1] = m
+			continue
+		}
+		installed = append(installed, m)
+	}
+	return installed, nil
+}
+
+// LoadModule returns the most recently installed record of the module called name.
+func LoadModule(db *database.Database, name string) (*Module, error) {
+	row := db.QueryRow(selectModules+` WHERE name = ? ORDER BY time DESC LIMIT 1`, name)
+
+	mod, err := scanModule(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrNotTracked, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if mod.Graph, err = LoadGraph(db, name); err != nil {
+		return nil, err
+	}
+	return mod, nil
+}
+
+// Load fills m with the most recently installed record of the module called name.
+func (m *Module) Load(db *database.Database, name string) error {
+	stored, err := LoadModule(db, name)
+	if err != nil {
+		return err
+	}
+
+	m.fill(stored)
+	return nil
+}
+
+// LoadHistory returns every installed version of the module called name,
+// oldest first. The last one is current.
+func LoadHistory(db *database.Database, name string) ([]Module, error) {
+	rows, err := db.Query(selectModules+` WHERE name = ? ORDER BY time`, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query modules: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	var mods []Module
+	for rows.Next() {
+		mod, err := scanModule(rows)
+		if err != nil {
+			return nil, err
+		}
+		mods = append(mods, *mod)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(mods) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotTracked, name)
+	}
+	return mods, nil
+}
+
+// LoadVersion fills m with the record of the module called name at version,
+// including the dependency graph stored with it.
+func (m *Module) LoadVersion(db *database.Database, name, version string) error {
+	stored, err := scanModule(db.QueryRow(selectModules+` WHERE name = ? AND version = ?`, name, version))
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %s@%s", ErrNotTracked, name, version)
+	}
+	if err != nil {
+		return err
+	}
+
+	var graph []byte
+	if err := db.QueryRow(`SELECT graph FROM modules WHERE name = ? AND version = ?`, name, version).Scan(&graph); err != nil {
+		return fmt.Errorf("failed to query dependency graph: %w", err)
+	}
+	if len(graph) > 0 {
+		if err := json.Unmarshal(graph, &stored.Graph); err != nil {
+			return fmt.Errorf("failed to decode graph of %s@%s: %w", name, version, err)
+		}
+	}
+
+	m.fill(stored)
+	return nil
+}
+
+func (m *Module) fill(stored *Module) {
+	m.Name = stored.Name
+	m.Version = stored.Version
+	m.Versions = stored.Versions
+	m.Dependencies = stored.Dependencies
+	m.Hash = stored.Hash
+	m.Time = stored.Time
+	m.Query = stored.Query
+	m.GoVersion = stored.GoVersion
+	m.Binary = stored.Binary
+	m.Size = stored.Size
+	m.Tags = stored.Tags
+	m.Alias = stored.Alias
+	m.License = stored.License
+	m.Graph = stored.Graph
+}
+
+// Purge deletes every record of the module from the modules, dependencies,
+// dependency_edges and shims tables.
+func (m *Module) Purge(db *database.Database) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+				log.Println("rollback failed:", err)
+			}
+		}
+	}()
+
+	if _, err := tx.Exec(`DELETE FROM dependencies WHERE module_name = ?`, m.Name); err != nil {
+		return fmt.Errorf("failed to delete dependencies: %w", err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM dependency_edges WHERE module_name = ?`, m.Name); err != nil {
+		return fmt.Errorf("failed to delete dependency graph: %w", err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM shims WHERE module_name = ?`, m.Name); err != nil {
+		return fmt.Errorf("failed to delete shim: %w", err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM modules WHERE name = ?`, m.Name); err != nil {
+		return fmt.Errorf("failed to delete module: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	committed = true
+	return nil
+}
+
+// RecordVersions stores versions that were seen upstream for the first time
+// and refreshes the known versions of every record of the module.
+func (m *Module) RecordVersions(db *database.Database, versions []string, seen time.Time) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+				log.Println("rollback failed:", err)
+			}
+		}
+	}()
+
+	versionStmt := `
+		INSERT INTO module_versions (module_name, version, seen)
+		VALUES (?, ?, ?)
+		ON CONFLICT(module_name, version) DO NOTHING
+		`
+	for _, v := range versions {
+		if _, err := tx.Exec(versionStmt, m.Name, v, seen); err != nil {
+			return fmt.Errorf("failed to insert version: %w", err)
+		}
+	}
+
+	merged := mergeVersions(m.Versions, versions)
+	versionsJSON, err := json.Marshal(merged)
+	if err != nil {
+		return fmt.Errorf("failed to marshal versions: %w", err)
+	}
+
+	if _, err := tx.Exec(`UPDATE modules SET versions = ? WHERE name = ?`, versionsJSON, m.Name); err != nil {
+		return fmt.Errorf("failed to update versions: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	committed = true
+	m.Versions = merged
+	return nil
+}
+
+// ParseName returns the normalized module path of a command line argument,
+// dropping any version suffix.
+func ParseName(input string) string {
+	name, _ := splitModuleVersion(normalizeModulePath(input))
+	return name
+}
+
+// Latest returns the newest version known for the module.
+func (m *Module) Latest() string {
+	if len(m.Versions) > 0 {
+		return m.Versions[0]
+	}
+	return m.Version
+}
+
+// mergeVersions returns the union of a and b sorted from newest to oldest.
+func mergeVersions(a, b []string) []string {
+	seen := make(map[string]struct{}, len(a)+len(b))
+	merged := make([]string, 0, len(a)+len(b))
+	for _, v := range append(append([]string{}, a...), b...) {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		merged = append(merged, v)
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return semver.Compare(merged[i], merged[j]) > 0
+	})
+	return merged
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanModule(row scanner) (*Module, error) {
+	var (
+		mod          Module
+		versions     []byte
+		dependencies []byte
+		hash         sql.NullString
+		installed    sql.NullTime
+		query        sql.NullString
+		goVersion    sql.NullString
+		binary       sql.NullString
+		size         sql.NullInt64
+		tags         []byte
+		alias        sql.NullString
+		license      sql.NullString
+	)
+
+	if err := row.Scan(&mod.Name, &mod.Version, &versions, &dependencies, &hash, &installed, &query, &goVersion, &binary, &size, &tags, &alias, &license); err != nil {
+		return nil, err
+	}
+
+	if len(versions) > 0 {
+		if err := json.Unmarshal(versions, &mod.Versions); err != nil {
+			return nil, fmt.Errorf("failed to decode versions of %s: %w", mod.Name, err)
+		}
+	}
+
+	if len(dependencies) > 0 {
+		if err := json.Unmarshal(dependencies, &mod.Dependencies); err != nil {
+			return nil, fmt.Errorf("failed to decode dependencies of %s: %w", mod.Name, err)
+		}
+	}
+
+	if len(tags) > 0 {
+		if err := json.Unmarshal(tags, &mod.Tags); err != nil {
+			return nil, fmt.Errorf("failed to decode tags of %s: %w", mod.Name, err)
+		}
+	}
+
+	mod.Hash = hash.String
+	mod.Time = installed.Time
+	mod.Query = query.String
+	mod.GoVersion = goVersion.String
+	mod.Binary = binary.String
+	mod.Size = size.Int64
+	mod.Alias = alias.String
+	mod.License = license.String
+	return &mod, nil
+}
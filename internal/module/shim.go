@@ -0,0 +1,78 @@
+package module
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/inovacc/goinstall/internal/database"
+	"os"
+	"path/filepath"
+)
+
+// ShimEnabled reports whether the GOBIN entry of the module called name is a
+// shim, which picks the version to run per directory.
+func ShimEnabled(db *database.Database, name string) (bool, error) {
+	var enabled bool
+	if err := db.QueryRow(`SELECT COUNT(*) > 0 FROM shims WHERE module_name = ?`, name).Scan(&enabled); err != nil {
+		return false, fmt.Errorf("failed to query shims: %w", err)
+	}
+	return enabled, nil
+}
+
+// SetShim records whether the GOBIN entry of m is a shim. The entry itself
+// changes on the next Relink.
+func (m *Module) SetShim(db *database.Database, enabled bool) error {
+	query := `DELETE FROM shims WHERE module_name = ?`
+	if enabled {
+		query = `INSERT INTO shims (module_name) VALUES (?) ON CONFLICT(module_name) DO NOTHING`
+	}
+	if _, err := db.Exec(query, m.Name); err != nil {
+		return fmt.Errorf("failed to update shims: %w", err)
+	}
+	m.Shim = enabled
+	return nil
+}
+
+// LoadShimmed returns the current record of every module installed behind a
+// shim.
+func LoadShimmed(db *database.Database) ([]Module, error) {
+	rows, err := db.Query(`SELECT module_name FROM shims ORDER BY module_name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query shims: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	mods := make([]Module, 0, len(names))
+	for _, name := range names {
+		m, err := LoadModule(db, name)
+		if err != nil {
+			return nil, err
+		}
+		m.Shim = true
+		mods = append(mods, *m)
+	}
+	return mods, nil
+}
+
+// shimExecutable returns the goinstall executable a shim runs, with symlinks
+// resolved so the shim survives goinstall being reinstalled in place.
+func shimExecutable() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate goinstall: %w", err)
+	}
+	return filepath.EvalSymlinks(exe)
+}
@@ -0,0 +1,170 @@
+package module
+
+import (
+	"fmt"
+	"github.com/inovacc/goinstall/internal/database"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+	modpath "golang.org/x/mod/module"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// StoreDir returns the directory every installed version of every binary is
+// kept in, next to the database.
+func StoreDir() string {
+	return filepath.Join(filepath.Dir(viper.GetString("installPath")), "store")
+}
+
+// StorePath returns where the binary of m at its version and checksum is
+// kept: <store>/<module>/@v/<version>/<sha256>/<binary>, with the module path
+// and version case-encoded as in the module cache. A rebuild of a version
+// with other content, such as other build tags, gets an entry of its own, so
+// a stored binary is never overwritten.
+func (m *Module) StorePath() (string, error) {
+	if m.Hash == "" {
+		return "", fmt.Errorf("%s@%s has no recorded checksum", m.Name, m.Version)
+	}
+
+	dir, err := m.storeDir()
+	if err != nil {
+		return "", err
+	}
+
+	version, err := modpath.EscapeVersion(m.Version)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, version, m.Hash, binaryName(m.Name)), nil
+}
+
+// storeDir returns the directory holding the stored versions of m.
+func (m *Module) storeDir() (string, error) {
+	escaped, err := modpath.EscapePath(m.Name)
+	if err != nil {
+		return "", err
+	}
+
+	store, err := filepath.Abs(StoreDir())
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(store, filepath.FromSlash(escaped), "@v"), nil
+}
+
+// Stored reports whether the binary of m at its version and checksum is in
+// the store.
+func (m *Module) Stored(fs afero.Fs) bool {
+	path, err := m.StorePath()
+	if err != nil {
+		return false
+	}
+	ok, _ := afero.Exists(fs, path)
+	return ok
+}
+
+// Activate points the GOBIN entry of m at its binary in the store and records
+// m as the installed version, without fetching or building anything. The
+// stored binary has to match the checksum recorded when it was installed.
+func (m *Module) Activate(db *database.Database) error {
+	if err := m.verifyStored(); err != nil {
+		return err
+	}
+	if err := m.Relink(); err != nil {
+		return err
+	}
+	m.Time = time.Now()
+	return m.Report(db)
+}
+
+// Relink points the GOBIN entry of m at its binary in the store, or at the
+// goinstall shim when m.Shim is set, and records where the binary is run
+// from: the GOBIN entry, or the store behind a shim.
+func (m *Module) Relink() error {
+	path, err := m.StorePath()
+	if err != nil {
+		return err
+	}
+
+	target := path
+	if m.Shim {
+		if target, err = shimExecutable(); err != nil {
+			return err
+		}
+	}
+	if err := m.link(target); err != nil {
+		return err
+	}
+
+	m.Binary = m.BinaryPath()
+	if m.Shim {
+		m.Binary = path
+	}
+	return nil
+}
+
+// verifyStored checks that the binary of m is in the store with the checksum
+// recorded when it was installed.
+func (m *Module) verifyStored() error {
+	path, err := m.StorePath()
+	if err != nil {
+		return err
+	}
+
+	hash, _, err := HashFile(m.fs, path)
+	if err != nil {
+		return fmt.Errorf("%s@%s is not in the store: %w", m.Name, m.Version, err)
+	}
+	if hash != m.Hash {
+		return fmt.Errorf("stored binary of %s@%s does not match its recorded checksum", m.Name, m.Version)
+	}
+	return nil
+}
+
+// link replaces the GOBIN entry of m with a symlink to target, or with a copy
+// of it where symlinks cannot be created. The entry is swapped
+// with a rename, so the tool is never missing.
+func (m *Module) link(target string) error {
+	path := m.BinaryPath()
+	if err := m.fs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+
+	tmp := filepath.Join(filepath.Dir(path), ".goinstall-"+filepath.Base(path))
+	_ = m.fs.Remove(tmp)
+
+	linker, ok := m.fs.(afero.Linker)
+	if !ok || linker.SymlinkIfPossible(target, tmp) != nil {
+		if err := copyFile(m.fs, target, tmp); err != nil {
+			return fmt.Errorf("failed to copy binary from the store: %w", err)
+		}
+	}
+
+	if err := m.fs.Rename(tmp, path); err != nil {
+		_ = m.fs.Remove(tmp)
+		return fmt.Errorf("failed to link %s: %w", path, err)
+	}
+	return nil
+}
+
+func copyFile(fs afero.Fs, src, dst string) error {
+	in, err := fs.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func(f afero.File) {
+		_ = f.Close()
+	}(in)
+
+	out, err := fs.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
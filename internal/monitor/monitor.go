@@ -1,9 +1,18 @@
 package monitor
 
 import (
+	"context"
+	"fmt"
 	"github.com/inovacc/goinstall/internal/database"
+	"github.com/inovacc/goinstall/internal/installer"
+	"github.com/inovacc/goinstall/internal/module"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"log"
+	"slices"
+	"strings"
+	"time"
 )
 
 var afs afero.Fs
@@ -18,9 +27,136 @@ func Monitor(cmd *cobra.Command, args []string) error {
 		cobra.CheckErr(db.Close())
 	}(db)
 
-	return moduleMonitor(db)
+	logger := log.New(cmd.OutOrStdout(), "", log.LstdFlags)
+	once, _ := cmd.Flags().GetBool("once")
+
+	return moduleMonitor(cmd.Context(), logger, db, viper.GetDuration("monitor.interval"), once)
+}
+
+// moduleMonitor polls upstream versions of every tracked module until ctx is
+// cancelled, or a single time when once is set.
+func moduleMonitor(ctx context.Context, logger *log.Logger, db *database.Database, interval time.Duration, once bool) error {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	m, err := module.NewModule(ctx, afs, "go")
+	if err != nil {
+		return err
+	}
+
+	pols, err := loadPolicies(db)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	skipped := make(map[string]bool)
+	for {
+		if err := poll(ctx, logger, db, m, pols, skipped); err != nil {
+			return err
+		}
+
+		if once {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.Println("monitor stopped")
+			return nil
+		case <-ticker.C:
+		}
+	}
 }
 
-func moduleMonitor(db *database.Database) error {
+// poll checks every installed module once. skipped holds the failed targets
+// already logged, so each is only reported on the first poll that skips it.
+func poll(ctx context.Context, logger *log.Logger, db *database.Database, m *module.Module, pols *policies, skipped map[string]bool) error {
+	mods, err := module.LoadInstalled(db)
+	if err != nil {
+		return err
+	}
+
+	logger.Printf("checking %d modules for new versions", len(mods))
+	for _, tracked := range mods {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		lr, err := m.FetchVersions(tracked.Name)
+		if err != nil {
+			logger.Printf("%s: %v", tracked.Name, err)
+			continue
+		}
+
+		var fresh []string
+		for _, v := range lr.Versions {
+			if !slices.Contains(tracked.Versions, v) {
+				fresh = append(fresh, v)
+			}
+		}
+		if len(fresh) > 0 {
+			if err := tracked.RecordVersions(db, fresh, time.Now()); err != nil {
+				return err
+			}
+			logger.Printf("%s: new versions %s (installed %s)", tracked.Name, strings.Join(fresh, ", "), tracked.Version)
+		}
+
+		// The installed version decides, not what is new: an update held
+		// back on an earlier poll is still applied once it can be.
+		if err := applyPolicy(ctx, logger, db, &tracked, pols, lr.Versions, fresh, skipped); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// applyPolicy installs the newest version the module policy allows over the
+// installed one, if any, and records what was done; new versions the policy
+// does not install are recorded as a notification. Only database failures
+// are returned; a failed install is logged and recorded so the remaining
+// modules are still checked. A target that failed to install before is not
+// tried again, only a newer release is.
+func applyPolicy(ctx context.Context, logger *log.Logger, db *database.Database, tracked *module.Module, pols *policies, versions, fresh []string, skipped map[string]bool) error {
+	policy, err := pols.lookup(tracked.Name)
+	if err != nil {
+		return err
+	}
+
+	target := policy.Target(tracked.Version, versions)
+	if target == "" {
+		if len(fresh) == 0 {
+			return nil
+		}
+		return tracked.RecordEvent(db, fresh[0], "notify", fmt.Sprintf("policy %s: %s", policy, strings.Join(fresh, ", ")))
+	}
+
+	failed, err := tracked.HasEvent(db, target, "auto-install-failed", "install-failed")
+	if err != nil {
+		return err
+	}
+	if failed {
+		if key := tracked.Name + "@" + target; !skipped[key] {
+			skipped[key] = true
+			logger.Printf("%s: skipping %s, its install failed before", tracked.Name, target)
+		}
+		return nil
+	}
+
+	logger.Printf("%s: installing %s (policy %s)", tracked.Name, target, policy)
+
+	m, err := module.NewModule(ctx, afs, "go")
+	if err == nil {
+		err = installer.Install(ctx, logger.Writer(), db, m, fmt.Sprintf("%s@%s", tracked.Name, target))
+	}
+	if err != nil {
+		logger.Printf("%s: install of %s failed: %v", tracked.Name, target, err)
+		return tracked.RecordEvent(db, target, "auto-install-failed", err.Error())
+	}
+
+	logger.Printf("%s: updated from %s to %s", tracked.Name, tracked.Version, target)
+	return tracked.RecordEvent(db, target, "auto-install", fmt.Sprintf("policy %s: %s -> %s", policy, tracked.Version, target))
+}
@@ -0,0 +1,70 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"github.com/inovacc/goinstall/internal/database"
+	"github.com/inovacc/goinstall/internal/module"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+	"log"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplyPolicy_failedTarget(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("GOPROXY", "off")
+	t.Setenv("GOBIN", filepath.Join(dir, "bin"))
+	viper.Set("installPath", filepath.Join(dir, "modules.db"))
+
+	afs = afero.NewOsFs()
+	db, err := database.NewDatabase(context.TODO(), afs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(db *database.Database) {
+		_ = db.Close()
+	}(db)
+
+	var out bytes.Buffer
+	logger := log.New(&out, "", 0)
+	pols := &policies{db: db, fallback: PolicyPatch}
+	tracked := &module.Module{Name: "example.com/tool", Version: "v1.0.0"}
+	versions := []string{"v1.0.1", "v1.0.0"}
+	skipped := make(map[string]bool)
+
+	failures := func() int {
+		var n int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM events WHERE module_name = ? AND version = ? AND action = ?`, tracked.Name, "v1.0.1", "auto-install-failed").Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	for poll := 1; poll <= 3; poll++ {
+		if err := applyPolicy(context.TODO(), logger, db, tracked, pols, versions, nil, skipped); err != nil {
+			t.Fatal(err)
+		}
+		if n := failures(); n != 1 {
+			t.Fatalf("poll %d: expected one failed install of v1.0.1, got %d", poll, n)
+		}
+	}
+
+	if n := strings.Count(out.String(), "installing v1.0.1"); n != 1 {
+		t.Errorf("expected a single install attempt, got %d:\n%s", n, out.String())
+	}
+	if n := strings.Count(out.String(), "skipping v1.0.1"); n != 1 {
+		t.Errorf("expected the skip to be logged once, got %d:\n%s", n, out.String())
+	}
+
+	// A newer release is tried even though the previous target failed.
+	versions = append([]string{"v1.0.2"}, versions...)
+	if err := applyPolicy(context.TODO(), logger, db, tracked, pols, versions, []string{"v1.0.2"}, skipped); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "installing v1.0.2") {
+		t.Errorf("expected v1.0.2 to be tried:\n%s", out.String())
+	}
+}
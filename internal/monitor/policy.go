@@ -0,0 +1,120 @@
+package monitor
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/inovacc/goinstall/internal/database"
+	"github.com/spf13/viper"
+	"golang.org/x/mod/semver"
+)
+
+// Policy decides what the monitor does when a new version of a module shows
+// up. A new major version is never installed automatically.
+type Policy string
+
+const (
+	// PolicyNotify only logs and records new versions.
+	PolicyNotify Policy = "notify"
+	// PolicyPatch installs new patch releases of the installed minor version.
+	PolicyPatch Policy = "patch"
+	// PolicyMinor installs new minor and patch releases of the installed major version.
+	PolicyMinor Policy = "minor"
+)
+
+// policyEntry is one item of the monitor.policies config list. A list is
+// used instead of a map because viper lowercases map keys and module paths
+// are case-sensitive.
+type policyEntry struct {
+	Module string `mapstructure:"module"`
+	Policy string `mapstructure:"policy"`
+}
+
+// ParsePolicy parses a policy name from the config file; an empty name is
+// PolicyNotify.
+func ParsePolicy(s string) (Policy, error) {
+	switch p := Policy(s); p {
+	case PolicyNotify, PolicyPatch, PolicyMinor:
+		return p, nil
+	case "":
+		return PolicyNotify, nil
+	default:
+		return "", fmt.Errorf("unknown policy %q (want %s, %s or %s)", s, PolicyNotify, PolicyPatch, PolicyMinor)
+	}
+}
+
+// Target returns the newest release in versions that the policy allows to
+// replace current, or an empty string when nothing should be installed.
+func (p Policy) Target(current string, versions []string) string {
+	if p == PolicyNotify || !semver.IsValid(current) {
+		return ""
+	}
+
+	target := ""
+	for _, v := range versions {
+		if !semver.IsValid(v) || semver.Prerelease(v) != "" || semver.Compare(v, current) <= 0 {
+			continue
+		}
+		if semver.Major(v) != semver.Major(current) {
+			continue
+		}
+		if p == PolicyPatch && semver.MajorMinor(v) != semver.MajorMinor(current) {
+			continue
+		}
+		if target == "" || semver.Compare(v, target) > 0 {
+			target = v
+		}
+	}
+	return target
+}
+
+// policies resolves the policy of each module from the database, falling
+// back to the configured default.
+type policies struct {
+	db       *database.Database
+	fallback Policy
+}
+
+// loadPolicies stores the policies from the config file in the database and
+// returns a resolver for them.
+func loadPolicies(db *database.Database) (*policies, error) {
+	fallback, err := ParsePolicy(viper.GetString("monitor.policy"))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []policyEntry
+	if err := viper.UnmarshalKey("monitor.policies", &entries); err != nil {
+		return nil, fmt.Errorf("failed to read monitor.policies: %w", err)
+	}
+
+	stmt := `
+		INSERT INTO policies (module_name, policy)
+		VALUES (?, ?)
+		ON CONFLICT(module_name) DO UPDATE
+		SET policy = excluded.policy
+		`
+	for _, e := range entries {
+		p, err := ParsePolicy(e.Policy)
+		if err != nil {
+			return nil, fmt.Errorf("policy of %s: %w", e.Module, err)
+		}
+		if _, err := db.Exec(stmt, e.Module, string(p)); err != nil {
+			return nil, fmt.Errorf("failed to store policy: %w", err)
+		}
+	}
+
+	return &policies{db: db, fallback: fallback}, nil
+}
+
+func (p *policies) lookup(name string) (Policy, error) {
+	var stored string
+	err := p.db.QueryRow(`SELECT policy FROM policies WHERE module_name = ?`, name).Scan(&stored)
+	if errors.Is(err, sql.ErrNoRows) {
+		return p.fallback, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to load policy: %w", err)
+	}
+	return ParsePolicy(stored)
+}
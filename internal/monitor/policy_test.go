@@ -0,0 +1,37 @@
+package monitor
+
+import "testing"
+
+func TestPolicy_Target(t *testing.T) {
+	versions := []string{"v2.0.0", "v1.5.0-rc.1", "v1.4.0", "v1.3.2", "v1.3.1", "v1.3.0"}
+
+	tests := []struct {
+		policy  Policy
+		current string
+		want    string
+	}{
+		{PolicyNotify, "v1.3.0", ""},
+		{PolicyPatch, "v1.3.0", "v1.3.2"},
+		{PolicyPatch, "v1.3.2", ""},
+		{PolicyMinor, "v1.3.0", "v1.4.0"},
+		{PolicyMinor, "v1.4.0", ""},
+		{PolicyMinor, "v2.0.0", ""},
+		{PolicyMinor, "(devel)", ""},
+	}
+
+	for _, tt := range tests {
+		if got := tt.policy.Target(tt.current, versions); got != tt.want {
+			t.Errorf("%s.Target(%s) = %q, want %q", tt.policy, tt.current, got, tt.want)
+		}
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	if p, err := ParsePolicy(""); err != nil || p != PolicyNotify {
+		t.Fatalf("expected empty policy to default to notify, got %q, %v", p, err)
+	}
+
+	if _, err := ParsePolicy("major"); err == nil {
+		t.Fatal("expected an error for an unknown policy")
+	}
+}
@@ -0,0 +1,119 @@
+package report
+
+import (
+	"fmt"
+	"github.com/inovacc/goinstall/internal/database"
+	"github.com/inovacc/goinstall/internal/module"
+	"github.com/inovacc/goinstall/internal/printer"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+var afs afero.Fs
+
+type moduleSummary struct {
+	Name    string    `json:"name"`
+	Version string    `json:"version"`
+	Time    time.Time `json:"time"`
+	Latest  string    `json:"latest"`
+	License string    `json:"license,omitempty"`
+}
+
+func Report(cmd *cobra.Command, args []string) error {
+	afs = afero.NewOsFs()
+
+	db, err := database.NewDatabase(cmd.Context(), afs)
+	if err != nil {
+		return err
+	}
+	defer func(db *database.Database) {
+		cobra.CheckErr(db.Close())
+	}(db)
+
+	format, _ := cmd.Flags().GetString("output")
+
+	if len(args) == 0 {
+		return listModules(cmd, db, format)
+	}
+	return showModule(cmd, db, format, module.ParseName(args[0]))
+}
+
+func listModules(cmd *cobra.Command, db *database.Database, format string) error {
+	mods, err := module.LoadModules(db)
+	if err != nil {
+		return err
+	}
+
+	summaries := make([]moduleSummary, 0, len(mods))
+	for _, m := range mods {
+		summaries = append(summaries, moduleSummary{
+			Name:    m.Name,
+			Version: m.Version,
+			Time:    m.Time,
+			Latest:  m.Latest(),
+			License: m.License,
+		})
+	}
+
+	return printer.Print(cmd.OutOrStdout(), format, summaries, func(tw *tabwriter.Writer) {
+		_, _ = fmt.Fprintln(tw, "NAME\tVERSION\tINSTALLED\tLATEST\tLICENSE")
+		for _, s := range summaries {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", s.Name, s.Version, formatTime(s.Time), s.Latest, orNone(s.License))
+		}
+	})
+}
+
+func showModule(cmd *cobra.Command, db *database.Database, format, name string) error {
+	m, err := module.LoadModule(db, name)
+	if err != nil {
+		return err
+	}
+
+	return printer.Print(cmd.OutOrStdout(), format, m, func(tw *tabwriter.Writer) {
+		_, _ = fmt.Fprintf(tw, "Name:\t%s\n", m.Name)
+		_, _ = fmt.Fprintf(tw, "Version:\t%s\n", m.Version)
+		_, _ = fmt.Fprintf(tw, "Latest:\t%s\n", m.Latest())
+		_, _ = fmt.Fprintf(tw, "Installed:\t%s\n", formatTime(m.Time))
+		if m.GoVersion != "" {
+			_, _ = fmt.Fprintf(tw, "Go:\t%s\n", m.GoVersion)
+		}
+		if len(m.Tags) > 0 {
+			_, _ = fmt.Fprintf(tw, "Tags:\t%s\n", strings.Join(m.Tags, ","))
+		}
+		if m.License != "" {
+			_, _ = fmt.Fprintf(tw, "License:\t%s\n", m.License)
+		}
+		if m.Binary != "" {
+			_, _ = fmt.Fprintf(tw, "Binary:\t%s (%d bytes)\n", m.Binary, m.Size)
+		}
+		_, _ = fmt.Fprintf(tw, "Hash:\t%s\n", m.Hash)
+		_, _ = fmt.Fprintf(tw, "Versions:\t%s\n", strings.Join(m.Versions, ", "))
+		_, _ = fmt.Fprintln(tw)
+
+		_, _ = fmt.Fprintln(tw, "DEPENDENCY\tVERSION\tLATEST\tLICENSE")
+		for _, d := range m.Dependencies {
+			latest := d.Version
+			if len(d.Versions) > 0 {
+				latest = d.Versions[0]
+			}
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", d.Name, d.Version, latest, orNone(d.License))
+		}
+	})
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format(time.DateTime)
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,90 @@
+package sbom
+
+import (
+	"encoding/json"
+	"io"
+	"slices"
+	"time"
+)
+
+type cdxDocument struct {
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	SerialNumber string          `json:"serialNumber"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies"`
+}
+
+type cdxMetadata struct {
+	Timestamp string        `json:"timestamp"`
+	Tools     cdxTools      `json:"tools"`
+	Component *cdxComponent `json:"component,omitempty"`
+}
+
+type cdxTools struct {
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	Type    string    `json:"type"`
+	BOMRef  string    `json:"bom-ref,omitempty"`
+	Name    string    `json:"name"`
+	Version string    `json:"version,omitempty"`
+	PURL    string    `json:"purl,omitempty"`
+	Hashes  []cdxHash `json:"hashes,omitempty"`
+}
+
+type cdxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+// writeCycloneDX writes b as a CycloneDX 1.5 JSON document. A bill of
+// materials of a single tool names it as the metadata component.
+func writeCycloneDX(w io.Writer, b *bom) error {
+	doc := cdxDocument{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + newUUID(),
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: b.created.Format(time.RFC3339),
+			Tools:     cdxTools{Components: []cdxComponent{{Type: "application", Name: "goinstall"}}},
+		},
+		Components:   make([]cdxComponent, 0, len(b.components)),
+		Dependencies: make([]cdxDependency, 0, len(b.components)),
+	}
+
+	for _, c := range b.components {
+		cc := cdxComponent{Type: "library", BOMRef: c.ref, Name: c.name, Version: c.version, PURL: c.ref}
+		if c.tool {
+			cc.Type = "application"
+		}
+		if c.hash != "" {
+			cc.Hashes = []cdxHash{{Alg: "SHA-256", Content: c.hash}}
+		}
+		doc.Components = append(doc.Components, cc)
+
+		dependsOn := slices.Clone(b.dependsOn[c.ref])
+		if dependsOn == nil {
+			dependsOn = []string{}
+		}
+		slices.Sort(dependsOn)
+		doc.Dependencies = append(doc.Dependencies, cdxDependency{Ref: c.ref, DependsOn: dependsOn})
+	}
+
+	if tools := b.tools(); len(tools) == 1 {
+		doc.Metadata.Component = &doc.Components[0]
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
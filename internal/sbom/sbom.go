@@ -0,0 +1,189 @@
+package sbom
+
+import (
+	"crypto/rand"
+	"fmt"
+	"github.com/inovacc/goinstall/internal/database"
+	"github.com/inovacc/goinstall/internal/graph"
+	"github.com/inovacc/goinstall/internal/module"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	FormatCycloneDX = "cyclonedx"
+	FormatSPDX      = "spdx"
+)
+
+var afs afero.Fs
+
+// component is a tool or module version of the bill of materials. ref is
+// its purl, which identifies it in both formats.
+type component struct {
+	ref     string
+	name    string
+	version string
+	// hash is the SHA-256 of the installed binary of a tool. The hash stored
+	// for dependencies identifies name@version rather than content, so
+	// dependencies have none.
+	hash string
+	tool bool
+}
+
+// bom is the format independent bill of materials of a set of tools.
+type bom struct {
+	name       string
+	created    time.Time
+	components []component
+	// dependsOn lists the direct dependencies of each component by ref.
+	dependsOn map[string][]string
+}
+
+// Sbom writes a CycloneDX or SPDX bill of materials of the named tool, or of
+// every installed tool, from the recorded modules, dependencies and graph.
+func Sbom(cmd *cobra.Command, args []string) error {
+	afs = afero.NewOsFs()
+
+	format, _ := cmd.Flags().GetString("format")
+	if format != FormatCycloneDX && format != FormatSPDX {
+		return fmt.Errorf("unknown sbom format %q (want %s or %s)", format, FormatCycloneDX, FormatSPDX)
+	}
+
+	db, err := database.NewDatabase(cmd.Context(), afs)
+	if err != nil {
+		return err
+	}
+	defer func(db *database.Database) {
+		cobra.CheckErr(db.Close())
+	}(db)
+
+	mods, err := graph.Load(db, args)
+	if err != nil {
+		return err
+	}
+
+	name := "goinstall-tools"
+	if len(args) > 0 {
+		name = mods[0].Name
+	}
+	return write(cmd.OutOrStdout(), format, build(name, mods, time.Now().UTC()))
+}
+
+// write renders b in format.
+func write(w io.Writer, format string, b *bom) error {
+	switch format {
+	case FormatSPDX:
+		return writeSPDX(w, b)
+	default:
+		return writeCycloneDX(w, b)
+	}
+}
+
+// build collects the tools and the modules they select. Relationships follow
+// the recorded requirement graph; a tool recorded without one, such as an
+// adopted binary, depends directly on each of its modules.
+func build(name string, mods []module.Module, created time.Time) *bom {
+	b := &bom{name: name, created: created.Truncate(time.Second), dependsOn: make(map[string][]string)}
+	g := graph.New(mods)
+
+	refs := make(map[string]string, len(g.Nodes))
+	seen := make(map[string]bool)
+	add := func(c component) string {
+		if !seen[c.ref] {
+			seen[c.ref] = true
+			b.components = append(b.components, c)
+		}
+		return c.ref
+	}
+
+	for _, m := range mods {
+		root := m.ModuleRoot()
+		hash := ""
+		if m.Binary != "" {
+			hash = m.Hash
+		}
+		ref := add(component{ref: purl(root, m.Version, m.Name), name: m.Name, version: m.Version, hash: hash, tool: true})
+		refs[m.Name+"@"+m.Version] = ref
+
+		for _, d := range m.Dependencies {
+			dep := add(component{ref: purl(d.Name, d.Version, ""), name: d.Name, version: d.Version})
+			refs[d.Name+"@"+d.Version] = dep
+			if len(m.Graph) == 0 {
+				b.link(ref, dep)
+			}
+		}
+	}
+
+	for _, l := range g.Links {
+		from, to := refs[l.From], refs[l.To]
+		if from == "" {
+			n := g.Nodes[l.From]
+			from = add(component{ref: purl(n.Path, n.Version, ""), name: n.Path, version: n.Version})
+		}
+		if to == "" {
+			n := g.Nodes[l.To]
+			to = add(component{ref: purl(n.Path, n.Version, ""), name: n.Path, version: n.Version})
+		}
+		b.link(from, to)
+	}
+
+	slices.SortStableFunc(b.components, func(a, c component) int {
+		if a.tool != c.tool {
+			if a.tool {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.ref, c.ref)
+	})
+	return b
+}
+
+func (b *bom) link(from, to string) {
+	if from != to && !slices.Contains(b.dependsOn[from], to) {
+		b.dependsOn[from] = append(b.dependsOn[from], to)
+	}
+}
+
+// tools returns the refs of the tools the bill of materials describes.
+func (b *bom) tools() []string {
+	var refs []string
+	for _, c := range b.components {
+		if c.tool {
+			refs = append(refs, c.ref)
+		}
+	}
+	return refs
+}
+
+// purl returns the package URL of module path at version. A package inside
+// the module, such as the command of a tool, is the subpath.
+func purl(path, version, pkg string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+
+	p := "pkg:golang/" + strings.Join(segments, "/")
+	if version != "" {
+		p += "@" + strings.ReplaceAll(url.PathEscape(version), "+", "%2B")
+	}
+	if sub := strings.TrimPrefix(pkg, path+"/"); pkg != "" && sub != pkg {
+		p += "#" + sub
+	}
+	return p
+}
+
+// newUUID returns a random version 4 UUID.
+func newUUID() string {
+	var u [16]byte
+	_, _ = rand.Read(u[:])
+	u[6] = u[6]&0x0f | 0x40
+	u[8] = u[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+}
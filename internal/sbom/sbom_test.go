@@ -0,0 +1,141 @@
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/inovacc/goinstall/internal/module"
+	"slices"
+	"testing"
+	"time"
+)
+
+func testModules() []module.Module {
+	return []module.Module{
+		{
+			Name:    "golang.org/x/tools/cmd/stringer",
+			Version: "v0.36.0",
+			Hash:    "4f2a",
+			Binary:  "/go/bin/stringer",
+			Dependencies: []module.Dependency{
+				{Name: "golang.org/x/tools", Version: "v0.36.0"},
+				{Name: "golang.org/x/mod", Version: "v0.27.0"},
+			},
+			Graph: []module.Edge{
+				{From: "golang.org/x/tools/cmd/stringer", FromVersion: "v0.36.0", To: "golang.org/x/tools", Version: "v0.36.0"},
+				{From: "golang.org/x/tools", FromVersion: "v0.36.0", To: "golang.org/x/mod", Version: "v0.26.0"},
+			},
+		},
+		{
+			Name:    "example.com/adopted",
+			Version: "v1.0.0+incompatible",
+			Hash:    "9c1b",
+			Dependencies: []module.Dependency{
+				{Name: "golang.org/x/mod", Version: "v0.27.0"},
+			},
+		},
+	}
+}
+
+func TestPurl(t *testing.T) {
+	tests := []struct {
+		path, version, pkg string
+		want               string
+	}{
+		{"golang.org/x/mod", "v0.27.0", "", "pkg:golang/golang.org/x/mod@v0.27.0"},
+		{"golang.org/x/tools", "v0.36.0", "golang.org/x/tools/cmd/stringer", "pkg:golang/golang.org/x/tools@v0.36.0#cmd/stringer"},
+		{"mvdan.cc/gofumpt", "v0.8.0", "mvdan.cc/gofumpt", "pkg:golang/mvdan.cc/gofumpt@v0.8.0"},
+		{"example.com/adopted", "v1.0.0+incompatible", "", "pkg:golang/example.com/adopted@v1.0.0%2Bincompatible"},
+	}
+
+	for _, tt := range tests {
+		if got := purl(tt.path, tt.version, tt.pkg); got != tt.want {
+			t.Errorf("purl(%q, %q, %q) = %s, want %s", tt.path, tt.version, tt.pkg, got, tt.want)
+		}
+	}
+}
+
+func TestWriteCycloneDX(t *testing.T) {
+	b := build("goinstall-tools", testModules(), time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	var out bytes.Buffer
+	if err := write(&out, FormatCycloneDX, b); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc cdxDocument
+	if err := json.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.BOMFormat != "CycloneDX" || doc.SpecVersion != "1.5" || doc.Metadata.Timestamp != "2025-01-02T03:04:05Z" {
+		t.Errorf("unexpected document header: %+v", doc)
+	}
+	if doc.Metadata.Component != nil {
+		t.Errorf("expected no metadata component for several tools, got %+v", doc.Metadata.Component)
+	}
+
+	var refs []string
+	for _, c := range doc.Components {
+		refs = append(refs, c.BOMRef)
+	}
+	want := []string{
+		"pkg:golang/example.com/adopted@v1.0.0%2Bincompatible",
+		"pkg:golang/golang.org/x/tools@v0.36.0#cmd/stringer",
+		"pkg:golang/golang.org/x/mod@v0.27.0",
+		"pkg:golang/golang.org/x/tools@v0.36.0",
+	}
+	if !slices.Equal(refs, want) {
+		t.Errorf("expected components %v, got %v", want, refs)
+	}
+
+	if doc.Components[0].Type != "application" || doc.Components[0].Hashes != nil {
+		t.Errorf("expected adopted tool without a recorded binary to have no hash, got %+v", doc.Components[0])
+	}
+	if h := doc.Components[1].Hashes; len(h) != 1 || h[0].Content != "4f2a" {
+		t.Errorf("expected stringer binary hash, got %+v", h)
+	}
+
+	deps := make(map[string][]string)
+	for _, d := range doc.Dependencies {
+		deps[d.Ref] = d.DependsOn
+	}
+	if got := deps[want[0]]; !slices.Equal(got, []string{want[2]}) {
+		t.Errorf("expected adopted tool to depend on its modules directly, got %v", got)
+	}
+	if got := deps[want[1]]; !slices.Equal(got, []string{want[3]}) {
+		t.Errorf("expected stringer to depend on its module, got %v", got)
+	}
+	if got := deps[want[3]]; !slices.Equal(got, []string{want[2]}) {
+		t.Errorf("expected x/tools to depend on the selected x/mod, got %v", got)
+	}
+}
+
+func TestWriteSPDX(t *testing.T) {
+	b := build("golang.org/x/tools/cmd/stringer", testModules()[:1], time.Now())
+
+	var out bytes.Buffer
+	if err := write(&out, FormatSPDX, b); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc spdxDocument
+	if err := json.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.SPDXVersion != "SPDX-2.3" || doc.SPDXID != spdxDocumentID || len(doc.Packages) != 3 {
+		t.Fatalf("unexpected document: %+v", doc)
+	}
+
+	tool := doc.Packages[0]
+	if tool.PrimaryPackagePurpose != "APPLICATION" || len(tool.Checksums) != 1 || tool.Checksums[0].ChecksumValue != "4f2a" {
+		t.Errorf("unexpected tool package: %+v", tool)
+	}
+
+	want := []spdxRelationship{
+		{SPDXElementID: spdxDocumentID, RelationshipType: "DESCRIBES", RelatedSPDXElement: "SPDXRef-Package-1"},
+		{SPDXElementID: "SPDXRef-Package-1", RelationshipType: "DEPENDS_ON", RelatedSPDXElement: "SPDXRef-Package-3"},
+		{SPDXElementID: "SPDXRef-Package-3", RelationshipType: "DEPENDS_ON", RelatedSPDXElement: "SPDXRef-Package-2"},
+	}
+	if !slices.Equal(doc.Relationships, want) {
+		t.Errorf("expected relationships %+v, got %+v", want, doc.Relationships)
+	}
+}
@@ -0,0 +1,110 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+)
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	SPDXID                string            `json:"SPDXID"`
+	Name                  string            `json:"name"`
+	VersionInfo           string            `json:"versionInfo,omitempty"`
+	DownloadLocation      string            `json:"downloadLocation"`
+	FilesAnalyzed         bool              `json:"filesAnalyzed"`
+	Checksums             []spdxChecksum    `json:"checksums,omitempty"`
+	ExternalRefs          []spdxExternalRef `json:"externalRefs"`
+	PrimaryPackagePurpose string            `json:"primaryPackagePurpose"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+const spdxDocumentID = "SPDXRef-DOCUMENT"
+
+// writeSPDX writes b as an SPDX 2.3 JSON document that describes the tools.
+// Packages are numbered in component order, as purls hold characters SPDX
+// identifiers do not allow.
+func writeSPDX(w io.Writer, b *bom) error {
+	doc := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            spdxDocumentID,
+		Name:              b.name,
+		DocumentNamespace: "https://spdx.org/spdxdocs/goinstall-" + newUUID(),
+		CreationInfo: spdxCreationInfo{
+			Created:  b.created.Format(time.RFC3339),
+			Creators: []string{"Tool: goinstall"},
+		},
+		Packages:      make([]spdxPackage, 0, len(b.components)),
+		Relationships: []spdxRelationship{},
+	}
+
+	ids := make(map[string]string, len(b.components))
+	for i, c := range b.components {
+		ids[c.ref] = fmt.Sprintf("SPDXRef-Package-%d", i+1)
+
+		p := spdxPackage{
+			SPDXID:                ids[c.ref],
+			Name:                  c.name,
+			VersionInfo:           c.version,
+			DownloadLocation:      "NOASSERTION",
+			ExternalRefs:          []spdxExternalRef{{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: c.ref}},
+			PrimaryPackagePurpose: "LIBRARY",
+		}
+		if c.tool {
+			p.PrimaryPackagePurpose = "APPLICATION"
+		}
+		if c.hash != "" {
+			p.Checksums = []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: c.hash}}
+		}
+		doc.Packages = append(doc.Packages, p)
+	}
+
+	for _, ref := range b.tools() {
+		doc.Relationships = append(doc.Relationships, spdxRelationship{SPDXElementID: spdxDocumentID, RelationshipType: "DESCRIBES", RelatedSPDXElement: ids[ref]})
+	}
+	for _, c := range b.components {
+		dependsOn := slices.Clone(b.dependsOn[c.ref])
+		slices.Sort(dependsOn)
+		for _, ref := range dependsOn {
+			doc.Relationships = append(doc.Relationships, spdxRelationship{SPDXElementID: ids[c.ref], RelationshipType: "DEPENDS_ON", RelatedSPDXElement: ids[ref]})
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
@@ -0,0 +1,220 @@
+package shim
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/inovacc/goinstall/internal/database"
+	"github.com/inovacc/goinstall/internal/module"
+	"github.com/spf13/afero"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// VersionFile pins the versions tools run at in a directory and the ones
+// below it. Each line holds a module path or binary name and a version:
+//
+//	# tools of this repository
+//	github.com/golangci/golangci-lint/cmd/golangci-lint v1.64.8
+//	protoc-gen-go v1.36.6
+const VersionFile = ".goinstall-version"
+
+// Run runs the tool a shim stands for when goinstall was started through one,
+// under the binary name of a tool whose GOBIN entry is a shim. It reports
+// whether it did; any other name, goinstall's own included, is left to the
+// command line, and the database is only opened for GOBIN entries that run
+// this executable.
+func Run(ctx context.Context, args []string) (bool, error) {
+	name := strings.TrimSuffix(filepath.Base(args[0]), ".exe")
+	if name == "goinstall" {
+		return false, nil
+	}
+
+	exe, err := os.Executable()
+	if err != nil || !isShim(filepath.Join(module.BinDir(), filepath.Base(args[0])), exe) {
+		return false, nil
+	}
+
+	path, matched, err := resolve(ctx, afero.NewOsFs(), name)
+	if !matched {
+		// Without a shimmed tool of this name the command line runs, and
+		// reports a database it cannot open itself.
+		return false, nil
+	}
+	if err != nil {
+		return true, err
+	}
+
+	// The tool gets the terminal; signals reach it through the process group.
+	cmd := exec.Command(path, args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return true, cmd.Run()
+}
+
+// isShim reports whether the GOBIN entry at path runs the executable exe: a
+// symlink to it, or the copy of it made where symlinks cannot be created.
+func isShim(path, exe string) bool {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false
+	}
+
+	if info.Mode()&os.ModeSymlink == 0 {
+		running, err := os.Stat(exe)
+		return err == nil && os.SameFile(info, running)
+	}
+
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return false
+	}
+	resolved, err := filepath.EvalSymlinks(exe)
+	return err == nil && target == resolved
+}
+
+// resolve returns the stored binary the shim called name runs in the current
+// directory. It reports whether a shimmed tool has that name; errors looking
+// the shims up come without one.
+func resolve(ctx context.Context, afs afero.Fs, name string) (string, bool, error) {
+	db, err := database.NewDatabase(ctx, afs)
+	if err != nil {
+		return "", false, err
+	}
+	defer func(db *database.Database) {
+		_ = db.Close()
+	}(db)
+
+	mods, err := module.LoadShimmed(db)
+	if err != nil {
+		return "", false, err
+	}
+
+	for i := range mods {
+		tool := &mods[i]
+		if strings.TrimSuffix(filepath.Base(tool.BinaryPath()), ".exe") != name {
+			continue
+		}
+
+		path, err := toolPath(db, afs, tool)
+		return path, true, err
+	}
+	return "", false, nil
+}
+
+// toolPath returns the stored binary of tool at the version pinned for the
+// current directory, or at its global default.
+func toolPath(db *database.Database, afs afero.Fs, tool *module.Module) (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	version, file, err := FindVersion(afs, dir, tool)
+	if err != nil {
+		return "", err
+	}
+	if version == "" {
+		version, file = tool.Version, "the global default"
+	}
+
+	var target module.Module
+	err = target.LoadVersion(db, tool.Name, version)
+	if err != nil && !errors.Is(err, module.ErrNotTracked) {
+		return "", err
+	}
+	if err != nil || !target.Stored(afs) {
+		return "", fmt.Errorf("%s@%s from %s is not installed, run: goinstall %s@%s", tool.Name, version, file, tool.Name, version)
+	}
+	return target.StorePath()
+}
+
+// FindVersion looks for the version of tool pinned in the version file of dir
+// or of the closest parent directory pinning it, and returns it with the
+// path of that file. Both are empty when no file pins the tool.
+func FindVersion(fs afero.Fs, dir string, tool *module.Module) (string, string, error) {
+	names := []string{tool.Name, strings.TrimSuffix(filepath.Base(tool.BinaryPath()), ".exe")}
+
+	for {
+		path := filepath.Join(dir, VersionFile)
+		data, err := afero.ReadFile(fs, path)
+		switch {
+		case err == nil:
+			pins, err := parse(data)
+			if err != nil {
+				return "", "", fmt.Errorf("%s: %w", path, err)
+			}
+			for _, n := range names {
+				if v, ok := pins[n]; ok {
+					return v, path, nil
+				}
+			}
+		case !errors.Is(err, os.ErrNotExist):
+			return "", "", err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", nil
+		}
+		dir = parent
+	}
+}
+
+// Pin sets the version of the module called name in the version file of dir,
+// keeping the other lines as they are.
+func Pin(fs afero.Fs, dir, name, version string) (string, error) {
+	path := filepath.Join(dir, VersionFile)
+	data, err := afero.ReadFile(fs, path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	var (
+		out    bytes.Buffer
+		pinned bool
+	)
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := sc.Text()
+		if fields := strings.Fields(line); len(fields) > 0 && fields[0] == name {
+			if pinned {
+				continue
+			}
+			line, pinned = name+" "+version, true
+		}
+		out.WriteString(line + "\n")
+	}
+	if err := sc.Err(); err != nil {
+		return "", err
+	}
+	if !pinned {
+		out.WriteString(name + " " + version + "\n")
+	}
+
+	return path, afero.WriteFile(fs, path, out.Bytes(), 0644)
+}
+
+func parse(data []byte) (map[string]string, error) {
+	pins := make(map[string]string)
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; sc.Scan(); n++ {
+		line, _, _ := strings.Cut(sc.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: want a tool and a version, got %q", n, strings.TrimSpace(line))
+		}
+
+		version := fields[1]
+		if !strings.HasPrefix(version, "v") {
+			version = "v" + version
+		}
+		pins[fields[0]] = version
+	}
+	return pins, sc.Err()
+}
@@ -0,0 +1,118 @@
+package shim
+
+import (
+	"github.com/inovacc/goinstall/internal/module"
+	"github.com/spf13/afero"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindVersion(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	files := map[string]string{
+		"/work/" + VersionFile:          "# tools\nprotoc-gen-go 1.34.2\n",
+		"/work/repo/" + VersionFile:     "mvdan.cc/gofumpt v0.8.0 # formatting\n",
+		"/work/repo/api/" + VersionFile: "google.golang.org/protobuf/cmd/protoc-gen-go v1.36.6\n",
+		"/broken/" + VersionFile:        "mvdan.cc/gofumpt\n",
+	}
+	for path, data := range files {
+		if err := afero.WriteFile(fs, filepath.FromSlash(path), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gofumpt := &module.Module{Name: "mvdan.cc/gofumpt"}
+	protoc := &module.Module{Name: "google.golang.org/protobuf/cmd/protoc-gen-go"}
+
+	tests := []struct {
+		dir     string
+		tool    *module.Module
+		version string
+		file    string
+	}{
+		{"/work/repo/internal", gofumpt, "v0.8.0", "/work/repo/" + VersionFile},
+		{"/work/repo/internal", protoc, "v1.34.2", "/work/" + VersionFile},
+		{"/work/repo/api", protoc, "v1.36.6", "/work/repo/api/" + VersionFile},
+		{"/work", gofumpt, "", ""},
+		{"/elsewhere", protoc, "", ""},
+	}
+
+	for _, tt := range tests {
+		version, file, err := FindVersion(fs, filepath.FromSlash(tt.dir), tt.tool)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if version != tt.version || file != filepath.FromSlash(tt.file) {
+			t.Errorf("FindVersion(%s, %s) = %s, %s, want %s, %s", tt.dir, tt.tool.Name, version, file, tt.version, tt.file)
+		}
+	}
+
+	if _, _, err := FindVersion(fs, filepath.FromSlash("/broken"), gofumpt); err == nil {
+		t.Error("expected an error for a malformed version file")
+	}
+}
+
+func TestPin(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	dir := filepath.FromSlash("/repo")
+	path := filepath.Join(dir, VersionFile)
+	if err := afero.WriteFile(fs, path, []byte("# tools\nmvdan.cc/gofumpt v0.8.0\nprotoc-gen-go v1.34.2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Pin(fs, dir, "mvdan.cc/gofumpt", "v0.9.2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Pin(fs, dir, "golang.org/x/tools/cmd/stringer", "v0.36.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := afero.ReadFile(fs, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# tools\nmvdan.cc/gofumpt v0.9.2\nprotoc-gen-go v1.34.2\ngolang.org/x/tools/cmd/stringer v0.36.0\n"
+	if string(data) != want {
+		t.Errorf("expected version file:\n%s\ngot:\n%s", want, data)
+	}
+}
+
+func TestIsShim(t *testing.T) {
+	dir := t.TempDir()
+	exe := filepath.Join(dir, "goinstall")
+	other := filepath.Join(dir, "gofumpt")
+	for _, path := range []string{exe, other} {
+		if err := os.WriteFile(path, []byte(filepath.Base(path)), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bin := filepath.Join(dir, "bin")
+	if err := os.Mkdir(bin, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(exe, filepath.Join(bin, "protoc-gen-go")); err != nil {
+		t.Skip("symlinks are not available:", err)
+	}
+	if err := os.Symlink(other, filepath.Join(bin, "gofumpt")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path, exe string
+		want      bool
+	}{
+		{filepath.Join(bin, "protoc-gen-go"), exe, true},
+		{filepath.Join(bin, "gofumpt"), exe, false},
+		{filepath.Join(bin, "missing"), exe, false},
+		// A copy is the running executable itself.
+		{other, other, true},
+		{other, exe, false},
+	}
+	for _, tt := range tests {
+		if got := isShim(tt.path, tt.exe); got != tt.want {
+			t.Errorf("isShim(%s, %s) = %v, want %v", tt.path, tt.exe, got, tt.want)
+		}
+	}
+}
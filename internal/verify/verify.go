@@ -0,0 +1,125 @@
+package verify
+
+import (
+	"debug/buildinfo"
+	"errors"
+	"fmt"
+	"github.com/inovacc/goinstall/internal/database"
+	"github.com/inovacc/goinstall/internal/module"
+	"github.com/inovacc/goinstall/internal/printer"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"os"
+	"text/tabwriter"
+)
+
+const (
+	StatusOK       = "ok"
+	StatusModified = "modified"
+	StatusReplaced = "replaced"
+	StatusMissing  = "missing"
+	// StatusUnrecorded is reported for modules installed before checksums
+	// were recorded. Reinstalling them records one.
+	StatusUnrecorded = "unrecorded"
+)
+
+var afs afero.Fs
+
+// Result is the outcome of verifying the binary of one installed module.
+type Result struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Binary  string `json:"binary"`
+	Status  string `json:"status"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+// Verify re-hashes the binary of every installed module, or of the given
+// ones, and compares it with the checksum recorded at install. Binaries that
+// were modified, replaced or deleted make it fail.
+func Verify(cmd *cobra.Command, args []string) error {
+	afs = afero.NewOsFs()
+
+	db, err := database.NewDatabase(cmd.Context(), afs)
+	if err != nil {
+		return err
+	}
+	defer func(db *database.Database) {
+		cobra.CheckErr(db.Close())
+	}(db)
+
+	var mods []module.Module
+	if len(args) == 0 {
+		if mods, err = module.LoadInstalled(db); err != nil {
+			return err
+		}
+	}
+	for _, name := range args {
+		m, err := module.LoadModule(db, module.ParseName(name))
+		if err != nil {
+			return err
+		}
+		mods = append(mods, *m)
+	}
+
+	results := make([]Result, 0, len(mods))
+	failed := 0
+	for i := range mods {
+		r := Check(afs, &mods[i])
+		if r.Status != StatusOK && r.Status != StatusUnrecorded {
+			failed++
+		}
+		results = append(results, r)
+	}
+
+	format, _ := cmd.Flags().GetString("output")
+	err = printer.Print(cmd.OutOrStdout(), format, results, func(tw *tabwriter.Writer) {
+		_, _ = fmt.Fprintln(tw, "NAME\tVERSION\tBINARY\tSTATUS\tDETAIL")
+		for _, r := range results {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", r.Name, r.Version, r.Binary, r.Status, r.Detail)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d of %d binaries failed verification", failed, len(results))
+	}
+	return nil
+}
+
+// Check compares the binary of m with its recorded checksum. A binary whose
+// checksum changed is reported as replaced when its build info names another
+// module or version, or none at all, and as modified otherwise.
+func Check(fs afero.Fs, m *module.Module) Result {
+	r := Result{Name: m.Name, Version: m.Version, Binary: m.Binary, Status: StatusOK}
+	if m.Binary == "" {
+		r.Binary, r.Status, r.Detail = m.BinaryPath(), StatusUnrecorded, "reinstall to record a checksum"
+		return r
+	}
+
+	hash, size, err := module.HashFile(fs, m.Binary)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		r.Status = StatusMissing
+		return r
+	case err != nil:
+		r.Status, r.Detail = StatusMissing, err.Error()
+		return r
+	case hash == m.Hash:
+		return r
+	}
+
+	r.Status, r.Detail = StatusModified, fmt.Sprintf("checksum %.12s, size %d, recorded %.12s, size %d", hash, size, m.Hash, m.Size)
+
+	info, err := buildinfo.ReadFile(m.Binary)
+	switch {
+	case err != nil:
+		r.Status, r.Detail = StatusReplaced, "not a Go binary"
+	case info.Path != m.Name || info.Main.Version != m.Version:
+		r.Status, r.Detail = StatusReplaced, fmt.Sprintf("built from %s@%s", info.Path, info.Main.Version)
+	}
+	return r
+}
@@ -0,0 +1,50 @@
+package verify
+
+import (
+	"debug/buildinfo"
+	"github.com/inovacc/goinstall/internal/module"
+	"github.com/spf13/afero"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	fs := afero.NewOsFs()
+	dir := t.TempDir()
+
+	tool := filepath.Join(dir, "tool")
+	if err := os.WriteFile(tool, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	hash, size, err := module.HashFile(fs, tool)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The test binary stands in for a Go binary rebuilt in place.
+	info, err := buildinfo.ReadFile(os.Args[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		m    module.Module
+		want string
+	}{
+		{"ok", module.Module{Name: "example.com/tool", Binary: tool, Hash: hash, Size: size}, StatusOK},
+		{"missing", module.Module{Name: "example.com/tool", Binary: filepath.Join(dir, "gone"), Hash: hash}, StatusMissing},
+		{"replaced", module.Module{Name: "example.com/tool", Binary: tool, Hash: "0000"}, StatusReplaced},
+		{"modified", module.Module{Name: info.Path, Version: info.Main.Version, Binary: os.Args[0], Hash: "0000"}, StatusModified},
+		{"unrecorded", module.Module{Name: "example.com/tool"}, StatusUnrecorded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if r := Check(fs, &tt.m); r.Status != tt.want {
+				t.Fatalf("expected %s but got %+v", tt.want, r)
+			}
+		})
+	}
+}